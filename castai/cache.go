@@ -0,0 +1,125 @@
+package castai
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+// listCacheTTL is short enough to only span a single Terraform operation, e.g. refresh of all resources in a workspace.
+const listCacheTTL = 30 * time.Second
+
+const (
+	listCacheEndpointNodeTemplates      = "node-templates"
+	listCacheEndpointNodeConfigurations = "node-configurations"
+)
+
+// listCacheKey returns the cache key of the cluster level list endpoint.
+func listCacheKey(endpoint, clusterID string) string {
+	return endpoint + "/" + clusterID
+}
+
+// errNotCacheable is returned by fetch functions together with a value which must be returned to the caller, but not
+// cached, e.g. API error responses.
+var errNotCacheable = errors.New("response is not cacheable")
+
+// responseCache memoizes API responses per key. Concurrent calls for the same key wait for a single in-flight request
+// instead of making their own. Errors aren't cached. Nil cache is valid and doesn't cache anything.
+type responseCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	lock    sync.Mutex
+	entries map[string]*responseCacheEntry
+}
+
+type responseCacheEntry struct {
+	done      chan struct{}
+	value     any
+	err       error
+	expiresAt time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*responseCacheEntry),
+	}
+}
+
+// get returns the cached value for the key, calling fetch when there is no valid value yet. Value returned together with
+// errNotCacheable is passed to the callers waiting for the same request, but isn't cached.
+func (c *responseCache) get(key string, fetch func() (any, error)) (any, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	c.lock.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		select {
+		case <-entry.done:
+			if c.now().After(entry.expiresAt) {
+				ok = false
+			}
+		default:
+			// Request is in flight.
+		}
+	}
+	if ok {
+		c.lock.Unlock()
+		<-entry.done
+		log.Printf("[DEBUG] Using cached response for %q", key)
+		return entry.value, entry.err
+	}
+
+	entry = &responseCacheEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	c.lock.Unlock()
+
+	entry.value, entry.err = fetch()
+	entry.expiresAt = c.now().Add(c.ttl)
+
+	c.lock.Lock()
+	if entry.err != nil && c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+	c.lock.Unlock()
+	close(entry.done)
+
+	return entry.value, entry.err
+}
+
+// invalidate drops the cached value for the key, it must be called after changes which affect the cached response.
+func (c *responseCache) invalidate(key string) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, key)
+}
+
+// getCachedResponse returns the cached API response for the key, calling fetch when there is no valid response yet. Only
+// successful responses are cached.
+func getCachedResponse[T sdk.Response](c *responseCache, key string, fetch func() (T, error)) (T, error) {
+	v, err := c.get(key, func() (any, error) {
+		resp, err := fetch()
+		if err == nil && resp.StatusCode() != http.StatusOK {
+			return resp, errNotCacheable
+		}
+		return resp, err
+	})
+	if err != nil && !errors.Is(err, errNotCacheable) {
+		var zero T
+		return zero, err
+	}
+
+	return v.(T), nil
+}
@@ -0,0 +1,130 @@
+package castai
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	t.Run("caches value until it expires", func(t *testing.T) {
+		r := require.New(t)
+		now := time.Date(2023, 4, 18, 10, 0, 0, 0, time.UTC)
+		cache := newResponseCache(time.Minute)
+		cache.now = func() time.Time { return now }
+
+		var calls int
+		fetch := func() (any, error) {
+			calls++
+			return calls, nil
+		}
+
+		v, err := cache.get("key", fetch)
+		r.NoError(err)
+		r.Equal(1, v)
+		v, err = cache.get("key", fetch)
+		r.NoError(err)
+		r.Equal(1, v)
+
+		now = now.Add(2 * time.Minute)
+		v, err = cache.get("key", fetch)
+		r.NoError(err)
+		r.Equal(2, v)
+	})
+
+	t.Run("doesn't cache errors", func(t *testing.T) {
+		r := require.New(t)
+		cache := newResponseCache(time.Minute)
+
+		var calls int
+		fetch := func() (any, error) {
+			calls++
+			if calls == 1 {
+				return "not found", errNotCacheable
+			}
+			if calls == 2 {
+				return nil, errors.New("timeout")
+			}
+			return "ok", nil
+		}
+
+		v, err := cache.get("key", fetch)
+		r.ErrorIs(err, errNotCacheable)
+		r.Equal("not found", v)
+		_, err = cache.get("key", fetch)
+		r.EqualError(err, "timeout")
+		v, err = cache.get("key", fetch)
+		r.NoError(err)
+		r.Equal("ok", v)
+		r.Equal(3, calls)
+	})
+
+	t.Run("invalidates value", func(t *testing.T) {
+		r := require.New(t)
+		cache := newResponseCache(time.Minute)
+
+		var calls int
+		fetch := func() (any, error) {
+			calls++
+			return calls, nil
+		}
+
+		_, err := cache.get("key", fetch)
+		r.NoError(err)
+		cache.invalidate("key")
+		v, err := cache.get("key", fetch)
+		r.NoError(err)
+		r.Equal(2, v)
+	})
+
+	t.Run("concurrent calls share a single request", func(t *testing.T) {
+		r := require.New(t)
+		cache := newResponseCache(time.Minute)
+
+		var calls int32
+		release := make(chan struct{})
+		fetch := func() (any, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return "ok", nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := cache.get("key", fetch)
+				r.NoError(err)
+				r.Equal("ok", v)
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		r.Equal(int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("nil cache doesn't cache", func(t *testing.T) {
+		r := require.New(t)
+		var cache *responseCache
+
+		var calls int
+		fetch := func() (any, error) {
+			calls++
+			return calls, nil
+		}
+
+		_, err := cache.get("key", fetch)
+		r.NoError(err)
+		_, err = cache.get("key", fetch)
+		r.NoError(err)
+		cache.invalidate("key")
+		r.Equal(2, calls)
+	})
+}
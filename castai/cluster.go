@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"net/http"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
 
 	"github.com/castai/terraform-provider-castai/castai/sdk"
 )
@@ -17,8 +20,122 @@ import (
 const (
 	FieldDeleteNodesOnDisconnect = "delete_nodes_on_disconnect"
 	FieldClusterCredentialsId    = "credentials_id"
+	FieldClusterMode             = "mode"
+	FieldClusterResumeOnboarding = "resume_onboarding"
+
+	// FieldClusterID references the cluster of cluster level resources. It's required and replaces the resource when
+	// changed, unless the resource allows referencing the cluster by name instead.
+	FieldClusterID = "cluster_id"
+
+	// FieldClusterName allows cluster level resources to reference the cluster by name, when its id isn't known while
+	// writing the configuration.
+	FieldClusterName = "cluster_name"
+)
+
+const (
+	ClusterModeReadOnly   = "read-only"
+	ClusterModeFullAccess = "full-access"
 )
 
+// clusterModeSchema returns schema of the cluster mode. Clusters are connected in read-only mode, CAST AI can only
+// manage nodes once it has credentials of the cluster.
+func clusterModeSchema(credentialsField string) *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{ClusterModeReadOnly, ClusterModeFullAccess}, false)),
+		Description: fmt.Sprintf("Whether CAST AI agent only monitors the cluster (`%s`) or can also manage its nodes (`%s`). "+
+			"`%s` mode requires `%s`. When not set, the mode follows whether `%s` is set. "+
+			"Cluster can't be switched back to `%s` mode once it has credentials.",
+			ClusterModeReadOnly, ClusterModeFullAccess, ClusterModeFullAccess, credentialsField, credentialsField, ClusterModeReadOnly),
+	}
+}
+
+// clusterMode returns the mode of the cluster with the given CAST AI credentials id.
+func clusterMode(credentialsID string) string {
+	if credentialsID == "" {
+		return ClusterModeReadOnly
+	}
+	return ClusterModeFullAccess
+}
+
+// clusterModeDiff checks that credentials in credentialsField match the configured cluster mode. When the mode isn't
+// configured, it's derived from the credentials after apply.
+func clusterModeDiff(credentialsField string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+		hasCredentials := isSetInConfig(d, credentialsField)
+		if !isSetInConfig(d, FieldClusterMode) {
+			if d.HasChange(credentialsField) && hasCredentials {
+				return d.SetNewComputed(FieldClusterMode)
+			}
+			return nil
+		}
+
+		switch d.Get(FieldClusterMode).(string) {
+		case ClusterModeFullAccess:
+			if !hasCredentials {
+				return fmt.Errorf("%q must be set in %s mode", credentialsField, ClusterModeFullAccess)
+			}
+		case ClusterModeReadOnly:
+			if hasCredentials {
+				return fmt.Errorf("%q can't be set in %s mode", credentialsField, ClusterModeReadOnly)
+			}
+			if d.Get(FieldClusterCredentialsId).(string) != "" {
+				return fmt.Errorf("cluster already has credentials, it can't be switched back to %s mode", ClusterModeReadOnly)
+			}
+		}
+
+		return nil
+	}
+}
+
+// clusterResumeOnboardingSchema returns schema of the flag which keeps registered clusters when onboarding fails later.
+func clusterResumeOnboardingSchema(credentialsField string) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+		Description: fmt.Sprintf("When updating `%s` of a newly registered cluster fails, report it as a warning and keep "+
+			"the cluster, so next apply continues onboarding by updating the credentials instead of registering the "+
+			"cluster again.", credentialsField),
+	}
+}
+
+// clusterTokenFailed reports that the cluster was registered, but its token couldn't be created. The resource is tainted,
+// so the cluster is deleted and registered again on next apply.
+func clusterTokenFailed(clusterID string, err error) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Cluster %s was registered, but creating its token failed", clusterID),
+		Detail: fmt.Sprintf("Completed onboarding phases: cluster registration. Failed phase: cluster token creation: %v.\n\n"+
+			"The cluster is registered again on next apply.", err),
+	}}
+}
+
+// clusterCredentialsFailed reports that the cluster was registered, but its credentials couldn't be updated. Credentials
+// are cleared in state, so they are sent again once the resource is kept with resume_onboarding or untainted.
+func clusterCredentialsFailed(data *schema.ResourceData, credentialsField string, err error) diag.Diagnostics {
+	if setErr := data.Set(credentialsField, ""); setErr != nil {
+		return diag.FromErr(fmt.Errorf("setting %s: %w", credentialsField, setErr))
+	}
+
+	d := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Cluster %s was registered, but updating its credentials failed", data.Id()),
+	}
+	next := fmt.Sprintf("The resource is tainted, so the cluster is registered again on next apply. Set `%s = true` "+
+		"or run `terraform untaint` to continue onboarding of the registered cluster instead.", FieldClusterResumeOnboarding)
+	if data.Get(FieldClusterResumeOnboarding).(bool) {
+		d.Severity = diag.Warning
+		next = "Next apply continues onboarding by updating the credentials."
+	}
+	d.Detail = fmt.Sprintf("Completed onboarding phases: cluster registration, cluster token creation. Failed phase: "+
+		"%s update: %v.\n\n%s", credentialsField, err, next)
+
+	return diag.Diagnostics{d}
+}
+
 func resourceCastaiClusterDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*ProviderConfig).api
 	clusterId := data.Id()
@@ -100,7 +217,7 @@ func fetchClusterData(ctx context.Context, client *sdk.ClientWithResponses, clus
 		return nil, err
 	}
 
-	if resp.StatusCode() == http.StatusNotFound {
+	if isNotFound(resp) {
 		log.Printf("[WARN] Removing cluster %s from state because it no longer exists in CAST AI", clusterID)
 		return nil, nil
 	}
@@ -143,3 +260,95 @@ func clusterTokenDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{
 
 	return diff.ForceNew(FieldClusterToken)
 }
+
+// updateClusterCredentials sends JSON encoded cluster credentials stored in credentialsField, when they have changed.
+func updateClusterCredentials(ctx context.Context, data *schema.ResourceData, client *sdk.ClientWithResponses, credentialsField string) error {
+	if !data.HasChanges(
+		credentialsField,
+	) {
+		log.Printf("[INFO] Nothing to update in cluster setttings.")
+		return nil
+	}
+
+	log.Printf("[INFO] Updating cluster settings.")
+
+	req := sdk.ExternalClusterAPIUpdateClusterJSONRequestBody{}
+
+	credentialsJSON, ok := data.GetOk(credentialsField)
+	if ok {
+		req.Credentials = toPtr(credentialsJSON.(string))
+	}
+
+	if err := backoff.Retry(func() error {
+		response, err := client.ExternalClusterAPIUpdateClusterWithResponse(ctx, data.Id(), req)
+		if err != nil {
+			return err
+		}
+		err = sdk.StatusOk(response)
+		// In case of malformed user request return error to user right away.
+		if response.StatusCode() == 400 {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.NewExponentialBackOff()); err != nil {
+		return fmt.Errorf("updating cluster configuration: %w", err)
+	}
+
+	return nil
+}
+
+// clusterHelmValues returns values CAST AI components have to be installed with to connect to the cluster.
+func clusterHelmValues(data *schema.ResourceData, meta interface{}, provider, organizationID string) map[string]string {
+	return map[string]string{
+		"apiKey":         data.Get(FieldClusterToken).(string),
+		"apiURL":         meta.(*ProviderConfig).apiURL,
+		"clusterID":      data.Id(),
+		"organizationID": organizationID,
+		"provider":       provider,
+	}
+}
+
+// resolveClusterID returns id of the cluster the resource belongs to. When the resource references the cluster by name,
+// the id is looked up at CAST AI and stored in the resource data.
+func resolveClusterID(ctx context.Context, d *schema.ResourceData, meta interface{}) (string, error) {
+	if clusterID := d.Get(FieldClusterID).(string); clusterID != "" {
+		return clusterID, nil
+	}
+
+	name := d.Get(FieldClusterName).(string)
+	if name == "" {
+		return "", fmt.Errorf("one of %q or %q must be set", FieldClusterID, FieldClusterName)
+	}
+
+	client := meta.(*ProviderConfig).api
+	resp, err := client.ExternalClusterAPIListClustersWithResponse(ctx, &sdk.ExternalClusterAPIListClustersParams{})
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return "", fmt.Errorf("listing clusters: %w", checkErr)
+	}
+
+	clusters := lo.Filter(lo.FromPtr(resp.JSON200.Items), func(c sdk.ExternalclusterV1Cluster, _ int) bool {
+		status := toString(c.Status)
+		return toString(c.Name) == name && status != sdk.ClusterStatusDeleted && status != sdk.ClusterStatusArchived
+	})
+	switch len(clusters) {
+	case 0:
+		return "", fmt.Errorf("cluster with name %q not found", name)
+	case 1:
+	default:
+		return "", fmt.Errorf("found %d clusters with name %q, use %q instead", len(clusters), name, FieldClusterID)
+	}
+
+	clusterID := toString(clusters[0].Id)
+	log.Printf("[INFO] Resolved cluster %q to id %s", name, clusterID)
+	if err := d.Set(FieldClusterID, clusterID); err != nil {
+		return "", fmt.Errorf("setting cluster id: %w", err)
+	}
+
+	return clusterID, nil
+}
+
+// clusterNameForceNew replaces the resource when it's moved to a cluster with another name. Setting the name on
+// resources which were created or imported with cluster id only doesn't replace them.
+var clusterNameForceNew = customdiff.ForceNewIfChange(FieldClusterName, func(_ context.Context, old, new, _ interface{}) bool {
+	return old.(string) != "" && new.(string) != ""
+})
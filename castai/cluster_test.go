@@ -0,0 +1,197 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestResolveClusterID(t *testing.T) {
+	clusters := `{"items": [
+		{"id": "b6bfc074-a267-400f-b8f1-db0850c369b1", "name": "production", "status": "ready"},
+		{"id": "0b7c5d2e-0ff5-4b3a-9d8e-8c5e3a4d5f60", "name": "production", "status": "archived"},
+		{"id": "5c8a2d16-6d2f-4a8e-bb4b-2f7c5e1d9a33", "name": "staging", "status": "ready"},
+		{"id": "9a6f3c1e-4c0b-4d2e-8f7a-1b2c3d4e5f60", "name": "staging", "status": "connecting"}
+	]}`
+
+	tests := map[string]struct {
+		config      map[string]interface{}
+		listed      bool
+		expectedID  string
+		expectedErr string
+	}{
+		"cluster id is set": {
+			config:     map[string]interface{}{FieldClusterID: "b6bfc074-a267-400f-b8f1-db0850c369b1"},
+			expectedID: "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		},
+		"archived clusters are ignored": {
+			config:     map[string]interface{}{FieldClusterName: "production"},
+			listed:     true,
+			expectedID: "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		},
+		"cluster not found": {
+			config:      map[string]interface{}{FieldClusterName: "development"},
+			listed:      true,
+			expectedErr: `cluster with name "development" not found`,
+		},
+		"several clusters with the same name": {
+			config:      map[string]interface{}{FieldClusterName: "staging"},
+			listed:      true,
+			expectedErr: `found 2 clusters with name "staging", use "cluster_id" instead`,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			if tt.listed {
+				mockClient.EXPECT().
+					ExternalClusterAPIListClusters(gomock.Any(), gomock.Any()).
+					Return(testJSONResponse(http.StatusOK, clusters), nil)
+			}
+
+			data := schema.TestResourceDataRaw(t, resourceNodeConfiguration().Schema, tt.config)
+			clusterID, err := resolveClusterID(context.Background(), data, provider)
+			if tt.expectedErr != "" {
+				r.ErrorContains(err, tt.expectedErr)
+				return
+			}
+			r.NoError(err)
+			r.Equal(tt.expectedID, clusterID)
+			r.Equal(tt.expectedID, data.Get(FieldClusterID))
+		})
+	}
+}
+
+func TestClusterNameForceNew(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	tests := map[string]struct {
+		stateName   string
+		configName  string
+		requiresNew bool
+	}{
+		"cluster name set on resource created with cluster id": {
+			configName: "production",
+		},
+		"cluster name changed": {
+			stateName:   "production",
+			configName:  "staging",
+			requiresNew: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			resource := resourceNodeConfiguration()
+
+			state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+				FieldClusterID:             cty.StringVal(clusterId),
+				FieldClusterName:           cty.StringVal(tt.stateName),
+				FieldNodeConfigurationName: cty.StringVal("default"),
+			}), 0)
+			state.ID = "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+
+			diff, err := resource.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+				FieldClusterName:           tt.configName,
+				FieldNodeConfigurationName: "default",
+			}), &ProviderConfig{})
+			r.NoError(err)
+			r.NotNil(diff)
+			r.Equal(tt.requiresNew, diff.RequiresNew())
+		})
+	}
+}
+
+func TestClusterModeDiff(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	credentials := `{"type": "service_account"}`
+
+	tests := map[string]struct {
+		stateCredentialsID string
+		config             map[string]string
+		expectedErr        string
+		modeComputed       bool
+	}{
+		"full access with credentials": {
+			config: map[string]string{FieldClusterMode: ClusterModeFullAccess, FieldGKEClusterCredentials: credentials},
+		},
+		"full access without credentials": {
+			config:      map[string]string{FieldClusterMode: ClusterModeFullAccess},
+			expectedErr: `"credentials_json" must be set in full-access mode`,
+		},
+		"read only with credentials": {
+			config:      map[string]string{FieldClusterMode: ClusterModeReadOnly, FieldGKEClusterCredentials: credentials},
+			expectedErr: `"credentials_json" can't be set in read-only mode`,
+		},
+		"read only on cluster with credentials": {
+			stateCredentialsID: "9b8d0456-177b-4a3d-b162-e68030d65GKE",
+			config:             map[string]string{FieldClusterMode: ClusterModeReadOnly},
+			expectedErr:        "cluster already has credentials, it can't be switched back to read-only mode",
+		},
+		"mode follows added credentials": {
+			config:       map[string]string{FieldGKEClusterCredentials: credentials},
+			modeComputed: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			resource := resourceGKECluster()
+
+			config := map[string]interface{}{
+				FieldGKEClusterName:      "gke-cluster",
+				FieldGKEClusterProjectId: "project",
+				FieldGKEClusterLocation:  "europe-central2-a",
+			}
+			rawConfig := map[string]cty.Value{}
+			for name, attrType := range resource.CoreConfigSchema().ImpliedType().AttributeTypes() {
+				rawConfig[name] = cty.NullVal(attrType)
+			}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			for k, v := range config {
+				rawConfig[k] = cty.StringVal(v.(string))
+			}
+
+			state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+				FieldGKEClusterName:          cty.StringVal("gke-cluster"),
+				FieldGKEClusterProjectId:     cty.StringVal("project"),
+				FieldGKEClusterLocation:      cty.StringVal("europe-central2-a"),
+				FieldClusterToken:            cty.StringVal("token"),
+				FieldGKEClusterCredentialsId: cty.StringVal(tt.stateCredentialsID),
+				FieldClusterMode:             cty.StringVal(clusterMode(tt.stateCredentialsID)),
+			}), 0)
+			state.ID = clusterId
+			state.RawConfig = cty.ObjectVal(rawConfig)
+
+			diff, err := resource.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), &ProviderConfig{})
+			if tt.expectedErr != "" {
+				r.ErrorContains(err, tt.expectedErr)
+				return
+			}
+			r.NoError(err)
+			if tt.modeComputed {
+				r.True(diff.Attributes[FieldClusterMode].NewComputed)
+			}
+		})
+	}
+}
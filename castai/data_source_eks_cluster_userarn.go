@@ -19,15 +19,18 @@ const (
 func dataSourceEKSClusterUserARN() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceCastaiEKSUserARN,
+		Description: "Retrieve ARN of the IAM user CAST AI uses to assume the cross account role of the cluster.",
 		Schema: map[string]*schema.Schema{
 			EKSClusterUserARNFieldClusterID: {
 				Type:             schema.TypeString,
 				Required:         true,
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "CAST AI cluster id.",
 			},
 			EKSClusterUserARNFieldARN: {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ARN of the IAM user, it has to be trusted by the cross account role.",
 			},
 		},
 	}
@@ -2,25 +2,44 @@ package castai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
 
 	"github.com/castai/terraform-provider-castai/castai/policies"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
-	EKSSettingsFieldAccountId          = "account_id"
-	EKSSettingsFieldRegion             = "region"
-	EKSSettingsFieldVpc                = "vpc"
-	EKSSettingsFieldCluster            = "cluster"
-	EKSSettingsFieldIamPolicyJson      = "iam_policy_json"
-	EKSSettingsFieldIamUserPolicyJson  = "iam_user_policy_json"
-	EKSSettingsFieldIamManagedPolicies = "iam_managed_policies"
+	EKSSettingsFieldAccountId           = "account_id"
+	EKSSettingsFieldRegion              = "region"
+	EKSSettingsFieldVpc                 = "vpc"
+	EKSSettingsFieldCluster             = "cluster"
+	EKSSettingsFieldIamPolicyJson       = "iam_policy_json"
+	EKSSettingsFieldIamPolicyJsonScoped = "iam_policy_json_scoped"
+	EKSSettingsFieldIamUserPolicyJson   = "iam_user_policy_json"
+	EKSSettingsFieldIamManagedPolicies  = "iam_managed_policies"
+	EKSSettingsFieldIamPath             = "iam_path"
+	EKSSettingsFieldRaw                 = "raw"
+	EKSSettingsFieldAWSProfile          = "aws_profile"
 )
 
+// newAWSSession creates AWS session of the shared config profile. It's a variable, so tests can point it to fake API.
+var newAWSSession = func(profile, region string) (*session.Session, error) {
+	return session.NewSessionWithOptions(session.Options{
+		Profile:           profile,
+		Config:            aws.Config{Region: aws.String(region)},
+		SharedConfigState: session.SharedConfigEnable,
+	})
+}
+
 func dataSourceEKSSettings() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceCastaiEKSSettingsRead,
@@ -50,10 +69,35 @@ func dataSourceEKSSettings() *schema.Resource {
 				ForceNew:         true,
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
 			},
+			EKSSettingsFieldIamPath: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  policies.DefaultIAMPath,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringMatch(regexp.MustCompile(`^/([\x21-\x7e]+/)?$`),
+					"must begin and end with a forward slash")),
+				Description: "IAM path of roles and instance profiles used by CAST AI, e.g. when IAM principals have to be " +
+					"created under a mandatory path. IAM policies only allow passing and changing roles and instance " +
+					"profiles of the path.",
+			},
+			EKSSettingsFieldAWSProfile: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description: "AWS shared config profile used to check that `account_id` and `vpc` exist before " +
+					"policies are returned, so typos fail early instead of onboarding a wrong account. Profiles with " +
+					"`role_arn` assume the role. When not set, nothing is checked and AWS isn't called.",
+			},
 			EKSSettingsFieldIamPolicyJson: {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			EKSSettingsFieldIamPolicyJsonScoped: {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "Variant of `iam_policy_json` for accounts where broad permissions are restricted by SCPs. " +
+					"Changes to existing IAM roles, instance profiles and EC2 resources are only allowed for resources " +
+					"tagged with `kubernetes.io/cluster/<cluster>` set to `owned` or `shared`.",
+			},
 			EKSSettingsFieldIamUserPolicyJson: {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -63,6 +107,12 @@ func dataSourceEKSSettings() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 				Computed: true,
 			},
+			EKSSettingsFieldRaw: {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "All settings as a JSON object keyed by attribute names, so modules can pass them around " +
+					"as a whole with `jsondecode`.",
+			},
 		},
 	}
 }
@@ -72,16 +122,27 @@ func dataSourceCastaiEKSSettingsRead(ctx context.Context, data *schema.ResourceD
 	vpc := data.Get(EKSSettingsFieldVpc).(string)
 	region := data.Get(EKSSettingsFieldRegion).(string)
 	cluster := data.Get(EKSSettingsFieldCluster).(string)
+	iamPath := data.Get(EKSSettingsFieldIamPath).(string)
+
+	if profile, ok := data.GetOk(EKSSettingsFieldAWSProfile); ok {
+		if err := checkEKSSettingsAWS(ctx, profile.(string), region, accountID, vpc); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	arn := fmt.Sprintf("%s:%s", region, accountID)
 
 	userPolicy, _ := policies.GetUserInlinePolicy(cluster, arn, vpc)
-	iamPolicy, _ := policies.GetIAMPolicy(accountID)
+	iamPolicy, _ := policies.GetIAMPolicy(accountID, iamPath)
+	scopedIAMPolicy, _ := policies.GetScopedIAMPolicy(accountID, cluster, iamPath)
 
 	data.SetId(fmt.Sprintf("eks-%s-%s-%s-%s", accountID, vpc, region, cluster))
 	if err := data.Set(EKSSettingsFieldIamPolicyJson, iamPolicy); err != nil {
 		return diag.FromErr(fmt.Errorf("setting iam policy: %w", err))
 	}
+	if err := data.Set(EKSSettingsFieldIamPolicyJsonScoped, scopedIAMPolicy); err != nil {
+		return diag.FromErr(fmt.Errorf("setting scoped iam policy: %w", err))
+	}
 	if err := data.Set(EKSSettingsFieldIamUserPolicyJson, userPolicy); err != nil {
 		return diag.FromErr(fmt.Errorf("setting iam user policy: %w", err))
 	}
@@ -89,6 +150,50 @@ func dataSourceCastaiEKSSettingsRead(ctx context.Context, data *schema.ResourceD
 		return diag.FromErr(fmt.Errorf("setting iam manged policies: %w", err))
 	}
 
+	raw, err := json.Marshal(map[string]interface{}{
+		EKSSettingsFieldAccountId:           accountID,
+		EKSSettingsFieldRegion:              region,
+		EKSSettingsFieldVpc:                 vpc,
+		EKSSettingsFieldCluster:             cluster,
+		EKSSettingsFieldIamPath:             iamPath,
+		EKSSettingsFieldIamPolicyJson:       iamPolicy,
+		EKSSettingsFieldIamPolicyJsonScoped: scopedIAMPolicy,
+		EKSSettingsFieldIamUserPolicyJson:   userPolicy,
+		EKSSettingsFieldIamManagedPolicies:  buildManagedPolicies(),
+	})
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("marshaling settings: %w", err))
+	}
+	if err := data.Set(EKSSettingsFieldRaw, string(raw)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting raw settings: %w", err))
+	}
+
+	return nil
+}
+
+// checkEKSSettingsAWS checks that credentials of the profile belong to the account and the VPC exists in the region.
+func checkEKSSettingsAWS(ctx context.Context, profile, region, accountID, vpc string) error {
+	sess, err := newAWSSession(profile, region)
+	if err != nil {
+		return fmt.Errorf("creating AWS session of profile %q: %w", profile, err)
+	}
+
+	identity, err := sts.New(sess).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return fmt.Errorf("getting AWS caller identity of profile %q: %w", profile, err)
+	}
+	if aws.StringValue(identity.Account) != accountID {
+		return fmt.Errorf("profile %q belongs to AWS account %s, not %s", profile, aws.StringValue(identity.Account), accountID)
+	}
+
+	vpcs, err := ec2.New(sess).DescribeVpcsWithContext(ctx, &ec2.DescribeVpcsInput{VpcIds: []*string{aws.String(vpc)}})
+	if err != nil {
+		return fmt.Errorf("describing VPC %s in %s: %w", vpc, region, err)
+	}
+	if len(vpcs.Vpcs) == 0 {
+		return fmt.Errorf("VPC %s not found in %s", vpc, region)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,95 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEKSSettingsDataSourceReadChecksAWS(t *testing.T) {
+	// Fake STS and EC2 APIs, only vpc-123 of account 123456789012 exists.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		switch req.Form.Get("Action") {
+		case "GetCallerIdentity":
+			_, _ = w.Write([]byte(`<GetCallerIdentityResponse><GetCallerIdentityResult>
+				<Arn>arn:aws:iam::123456789012:user/terraform</Arn><UserId>AIDA</UserId><Account>123456789012</Account>
+			</GetCallerIdentityResult></GetCallerIdentityResponse>`))
+		case "DescribeVpcs":
+			if req.Form.Get("VpcId.1") != "vpc-123" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`<Response><Errors><Error><Code>InvalidVpcID.NotFound</Code>
+					<Message>The vpc ID 'vpc-typo' does not exist</Message></Error></Errors></Response>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<DescribeVpcsResponse><vpcSet><item><vpcId>vpc-123</vpcId></item></vpcSet></DescribeVpcsResponse>`))
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	defer server.Close()
+
+	newSession := newAWSSession
+	defer func() { newAWSSession = newSession }()
+	newAWSSession = func(profile, region string) (*session.Session, error) {
+		require.Equal(t, "onboarding", profile)
+		return session.NewSession(&aws.Config{
+			Region:      aws.String(region),
+			Endpoint:    aws.String(server.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+			MaxRetries:  aws.Int(0),
+		})
+	}
+
+	tests := map[string]struct {
+		accountID string
+		vpc       string
+		err       string
+	}{
+		"existing account and VPC": {
+			accountID: "123456789012",
+			vpc:       "vpc-123",
+		},
+		"other account": {
+			accountID: "123456789013",
+			vpc:       "vpc-123",
+			err:       `profile "onboarding" belongs to AWS account 123456789012, not 123456789013`,
+		},
+		"missing VPC": {
+			accountID: "123456789012",
+			vpc:       "vpc-typo",
+			err:       "describing VPC vpc-typo in eu-central-1: InvalidVpcID.NotFound",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+
+			dataSource := dataSourceEKSSettings()
+			data := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]any{
+				EKSSettingsFieldAccountId:  tt.accountID,
+				EKSSettingsFieldRegion:     "eu-central-1",
+				EKSSettingsFieldVpc:        tt.vpc,
+				EKSSettingsFieldCluster:    "production",
+				EKSSettingsFieldAWSProfile: "onboarding",
+			})
+
+			result := dataSource.ReadContext(context.Background(), data, nil)
+			if tt.err == "" {
+				r.Nil(result)
+				r.NotEmpty(data.Get(EKSSettingsFieldIamPolicyJson))
+				return
+			}
+			r.True(result.HasError())
+			r.Contains(result[0].Summary, tt.err)
+			r.Empty(data.Id())
+		})
+	}
+}
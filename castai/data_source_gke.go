@@ -13,11 +13,13 @@ import (
 func dataSourceGKEPolicies() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceGKEPoliciesRead,
+		Description: "Retrieve IAM permissions of the GCP service account CAST AI uses to manage GKE clusters.",
 		Schema: map[string]*schema.Schema{
 			"policy": {
-				Type:     schema.TypeList,
-				Computed: true,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IAM permissions, e.g. for a custom role of the service account.",
 			},
 		},
 	}
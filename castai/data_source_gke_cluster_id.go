@@ -0,0 +1,94 @@
+package castai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	GKEClusterIDFieldProjectId   = "project_id"
+	GKEClusterIDFieldLocation    = "location"
+	GKEClusterIDFieldClusterName = "cluster_name"
+	GKEClusterIDFieldRaw         = "raw"
+)
+
+func dataSourceGKEClusterID() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceCastaiGKEClusterIDRead,
+		Description: "Retrieve CAST AI cluster id of the GKE cluster connected to CAST AI.",
+		Schema: map[string]*schema.Schema{
+			GKEClusterIDFieldProjectId: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "GCP project id of the cluster.",
+			},
+			GKEClusterIDFieldLocation: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "Region or zone of the cluster.",
+			},
+			GKEClusterIDFieldClusterName: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "GKE cluster name.",
+			},
+			GKEClusterIDFieldRaw: {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "The cluster as returned by CAST AI API, JSON encoded, so modules can pass it around as a whole " +
+					"with `jsondecode`.",
+			},
+		},
+	}
+}
+
+func dataSourceCastaiGKEClusterIDRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	projectID := data.Get(GKEClusterIDFieldProjectId).(string)
+	location := data.Get(GKEClusterIDFieldLocation).(string)
+	name := data.Get(GKEClusterIDFieldClusterName).(string)
+
+	resp, err := client.ExternalClusterAPIListClustersWithResponse(ctx, &sdk.ExternalClusterAPIListClustersParams{})
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(fmt.Errorf("listing clusters: %w", checkErr))
+	}
+
+	clusters := lo.Filter(lo.FromPtr(resp.JSON200.Items), func(c sdk.ExternalclusterV1Cluster, _ int) bool {
+		status := toString(c.Status)
+		if c.Gke == nil || status == sdk.ClusterStatusDeleted || status == sdk.ClusterStatusArchived {
+			return false
+		}
+		return toString(c.Gke.ProjectId) == projectID && toString(c.Gke.Location) == location && toString(c.Gke.ClusterName) == name
+	})
+	switch len(clusters) {
+	case 0:
+		return diag.Errorf("GKE cluster %q in project %q and location %q is not connected to CAST AI", name, projectID, location)
+	case 1:
+	default:
+		return diag.Errorf("found %d CAST AI clusters for GKE cluster %q in project %q and location %q", len(clusters), name, projectID, location)
+	}
+
+	raw, err := json.Marshal(clusters[0])
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("marshaling cluster: %w", err))
+	}
+
+	data.SetId(toString(clusters[0].Id))
+	if err := data.Set(GKEClusterIDFieldRaw, string(raw)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting raw cluster: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestGKEClusterIDDataSourceRead(t *testing.T) {
+	clusters := `{"items": [
+		{"id": "0b3c8a59-7e5e-4b8f-9a3a-0d3c3b1e5d10", "name": "prod", "status": "archived", "gke": {"projectId": "acme", "location": "europe-west1", "clusterName": "prod"}},
+		{"id": "b6bfc074-a267-400f-b8f1-db0850c369b1", "name": "prod", "status": "ready", "gke": {"projectId": "acme", "location": "europe-west1", "clusterName": "prod"}},
+		{"id": "5d7e3b1c-2a10-4e4c-9eb9-a9ab4a9c8f2c", "name": "prod", "status": "ready", "gke": {"projectId": "acme", "location": "us-central1", "clusterName": "prod"}},
+		{"id": "e5a44acc-85b2-4c3a-8318-dfc20d0111f9", "name": "prod", "status": "ready", "eks": {"accountId": "acme", "region": "europe-west1", "clusterName": "prod"}}
+	]}`
+
+	tests := map[string]struct {
+		location    string
+		expectedID  string
+		expectedErr string
+	}{
+		"cluster found": {
+			location:   "europe-west1",
+			expectedID: "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		},
+		"cluster not found": {
+			location:    "asia-east1",
+			expectedErr: `GKE cluster "prod" in project "acme" and location "asia-east1" is not connected to CAST AI`,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			mockClient.EXPECT().
+				ExternalClusterAPIListClusters(gomock.Any(), gomock.Any()).
+				Return(testJSONResponse(http.StatusOK, clusters), nil)
+
+			dataSource := dataSourceGKEClusterID()
+			data := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]any{
+				GKEClusterIDFieldProjectId:   "acme",
+				GKEClusterIDFieldLocation:    tt.location,
+				GKEClusterIDFieldClusterName: "prod",
+			})
+
+			result := dataSource.ReadContext(context.Background(), data, provider)
+			if tt.expectedErr != "" {
+				r.NotNil(result)
+				r.Equal(tt.expectedErr, result[0].Summary)
+				return
+			}
+			r.Nil(result)
+			r.Equal(tt.expectedID, data.Id())
+			r.JSONEq(`{"id": "b6bfc074-a267-400f-b8f1-db0850c369b1", "name": "prod", "status": "ready", "kubernetesVersion": null, "reconcileError": null, "reconciledAt": null, "sshPublicKey": null, "gke": {"projectId": "acme", "location": "europe-west1", "clusterName": "prod"}}`, data.Get(GKEClusterIDFieldRaw).(string))
+		})
+	}
+}
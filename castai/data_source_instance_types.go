@@ -0,0 +1,145 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	InstanceTypesFieldClusterID    = "cluster_id"
+	InstanceTypesFieldArchitecture = "architecture"
+	InstanceTypesFieldGPU          = "gpu"
+	InstanceTypesFieldInstanceType = "instance_types"
+)
+
+func dataSourceInstanceTypes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceCastaiInstanceTypesRead,
+		Description: "Retrieve instance types available in the region of the cluster.",
+		Schema: map[string]*schema.Schema{
+			InstanceTypesFieldClusterID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id. Instance types are listed for the region of the cluster.",
+			},
+			InstanceTypesFieldArchitecture: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{ArchAMD64, ArchARM64}, false)),
+				Description:      fmt.Sprintf("Only return instance types of the given CPU architecture. Allowed values: %s, %s.", ArchAMD64, ArchARM64),
+			},
+			InstanceTypesFieldGPU: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "When true, only instance types with GPUs are returned. When false, only instance types without GPUs are returned.",
+			},
+			InstanceTypesFieldInstanceType: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"family": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"architecture": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cpu": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "CPU cores.",
+						},
+						"memory": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Memory (MiB).",
+						},
+						"cpu_price": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "On-demand price of a single CPU core per hour.",
+						},
+						"gpu_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+				Description: "Instance types sorted by name.",
+			},
+		},
+	}
+}
+
+func dataSourceCastaiInstanceTypesRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	clusterID := data.Get(InstanceTypesFieldClusterID).(string)
+	filter := sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody{
+		Constraints: &sdk.NodetemplatesV1TemplateConstraints{},
+	}
+	if v, ok := data.GetOk(InstanceTypesFieldArchitecture); ok {
+		filter.Constraints.Architectures = &[]string{v.(string)}
+	}
+
+	resp, err := client.NodeTemplatesAPIFilterInstanceTypesWithResponse(ctx, clusterID, filter)
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(checkErr)
+	}
+
+	gpu, gpuSet := data.GetOkExists(InstanceTypesFieldGPU)
+	instanceTypes := make([]map[string]interface{}, 0)
+	for _, t := range lo.FromPtr(resp.JSON200.AvailableInstanceTypes) {
+		gpuCount := lo.SumBy(lo.FromPtr(t.AvailableGpuDevices), func(d sdk.NodetemplatesV1AvailableInstanceTypeGPUDevice) int32 {
+			return lo.FromPtr(d.Count)
+		})
+		if gpuSet && gpu.(bool) != (gpuCount > 0) {
+			continue
+		}
+
+		instanceTypes = append(instanceTypes, map[string]interface{}{
+			"name":         lo.FromPtr(t.Name),
+			"family":       lo.FromPtr(t.Family),
+			"architecture": lo.FromPtr(t.Architecture),
+			"cpu":          parseQuantity(t.Cpu),
+			"memory":       parseQuantity(t.Memory),
+			"cpu_price":    lo.FromPtr(t.CpuCost),
+			"gpu_count":    int(gpuCount),
+		})
+	}
+	sort.Slice(instanceTypes, func(i, j int) bool {
+		return instanceTypes[i]["name"].(string) < instanceTypes[j]["name"].(string)
+	})
+
+	data.SetId(clusterID)
+	if err := data.Set(InstanceTypesFieldInstanceType, instanceTypes); err != nil {
+		return diag.FromErr(fmt.Errorf("setting instance types: %w", err))
+	}
+
+	return nil
+}
+
+// parseQuantity converts numeric API string value to int, values which can't be parsed are returned as 0.
+func parseQuantity(v *string) int {
+	n, err := strconv.Atoi(lo.FromPtr(v))
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestInstanceTypesDataSourceRead(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal([]string{ArchAMD64}, lo.FromPtr(req.Constraints.Architectures))
+
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": [
+				{"name": "p3.2xlarge", "family": "p3", "architecture": "amd64", "cpu": "8", "memory": "62464", "cpuCost": 0.3825,
+				 "availableGpuDevices": [{"manufacturer": "NVIDIA", "name": "V100", "count": 1}]},
+				{"name": "m5.large", "family": "m5", "architecture": "amd64", "cpu": "2", "memory": "8192", "cpuCost": 0.048},
+				{"name": "g4dn.12xlarge", "family": "g4dn", "architecture": "amd64", "cpu": "48", "memory": "196608", "cpuCost": 0.0815,
+				 "availableGpuDevices": [{"manufacturer": "NVIDIA", "name": "T4", "count": 4}]}
+			]}`), nil
+		})
+
+	dataSource := dataSourceInstanceTypes()
+	data := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]any{
+		InstanceTypesFieldClusterID:    clusterId,
+		InstanceTypesFieldArchitecture: ArchAMD64,
+		InstanceTypesFieldGPU:          true,
+	})
+
+	result := dataSource.ReadContext(context.Background(), data, provider)
+	r.Nil(result)
+	r.Equal(clusterId, data.Id())
+	r.Equal([]any{
+		map[string]any{"name": "g4dn.12xlarge", "family": "g4dn", "architecture": "amd64", "cpu": 48, "memory": 196608, "cpu_price": 0.0815, "gpu_count": 4},
+		map[string]any{"name": "p3.2xlarge", "family": "p3", "architecture": "amd64", "cpu": 8, "memory": 62464, "cpu_price": 0.3825, "gpu_count": 1},
+	}, data.Get(InstanceTypesFieldInstanceType))
+}
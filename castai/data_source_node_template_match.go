@@ -0,0 +1,175 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	NodeTemplateMatchFieldClusterID     = "cluster_id"
+	NodeTemplateMatchFieldNodeSelector  = "node_selector"
+	NodeTemplateMatchFieldTolerations   = "tolerations"
+	NodeTemplateMatchFieldNodeTemplates = "node_templates"
+)
+
+const (
+	// nodeTemplateLabelKey is the label and taint key CAST AI sets on nodes of a template to the template name.
+	nodeTemplateLabelKey = "scheduling.cast.ai/node-template"
+
+	tolerationOperatorEqual  = "Equal"
+	tolerationOperatorExists = "Exists"
+
+	// taintEffectNoSchedule is the only effect of node template taints.
+	taintEffectNoSchedule = "NoSchedule"
+)
+
+func dataSourceNodeTemplateMatch() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNodeTemplateMatchRead,
+		Description: "Dry run of node template selection: returns node templates whose nodes a pod with the given node " +
+			"selector and tolerations can be scheduled on. A template matches when its nodes have all labels of the " +
+			"node selector and the pod tolerates all taints of the template.",
+		Schema: map[string]*schema.Schema{
+			NodeTemplateMatchFieldClusterID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id.",
+			},
+			NodeTemplateMatchFieldNodeSelector: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Node selector of the pod.",
+			},
+			NodeTemplateMatchFieldTolerations: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Taint key the toleration applies to, empty key with `Exists` operator tolerates all taints.",
+						},
+						"operator": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          tolerationOperatorEqual,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{tolerationOperatorEqual, tolerationOperatorExists}, false)),
+							Description:      fmt.Sprintf("One of `%s` or `%s`.", tolerationOperatorEqual, tolerationOperatorExists),
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Taint value the toleration matches with `Equal` operator.",
+						},
+						"effect": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{taintEffectNoSchedule, "PreferNoSchedule", "NoExecute"}, false)),
+							Description:      "Taint effect the toleration matches, empty effect matches all effects.",
+						},
+					},
+				},
+				Description: "Tolerations of the pod.",
+			},
+			NodeTemplateMatchFieldNodeTemplates: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted names of the matching node templates.",
+			},
+		},
+	}
+}
+
+func dataSourceNodeTemplateMatchRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clusterID := data.Get(NodeTemplateMatchFieldClusterID).(string)
+
+	resp, err := listNodeTemplates(ctx, meta, clusterID)
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(fmt.Errorf("listing node templates: %w", checkErr))
+	}
+
+	nodeSelector := toStringMap(data.Get(NodeTemplateMatchFieldNodeSelector).(map[string]interface{}))
+	tolerations := lo.Map(data.Get(NodeTemplateMatchFieldTolerations).([]interface{}), func(v interface{}, _ int) map[string]interface{} {
+		return v.(map[string]interface{})
+	})
+
+	var names []string
+	for _, item := range lo.FromPtr(resp.JSON200.Items) {
+		if item.Template == nil {
+			continue
+		}
+		if matchesNodeTemplate(*item.Template, nodeSelector, tolerations) {
+			names = append(names, lo.FromPtr(item.Template.Name))
+		}
+	}
+	sort.Strings(names)
+
+	data.SetId(clusterID)
+	if err := data.Set(NodeTemplateMatchFieldNodeTemplates, names); err != nil {
+		return diag.FromErr(fmt.Errorf("setting node templates: %w", err))
+	}
+
+	return nil
+}
+
+// matchesNodeTemplate returns true when a pod with the node selector and tolerations can be scheduled on nodes of the
+// template.
+func matchesNodeTemplate(t sdk.NodetemplatesV1NodeTemplate, nodeSelector map[string]string, tolerations []map[string]interface{}) bool {
+	labels := withCustomLabel(lo.FromPtr(t.CustomLabels).AdditionalProperties, t.CustomLabel)
+	labels[nodeTemplateLabelKey] = lo.FromPtr(t.Name)
+	for k, v := range nodeSelector {
+		if label, ok := labels[k]; !ok || label != v {
+			return false
+		}
+	}
+
+	return lo.EveryBy(nodeTemplateTaints(t), func(taint sdk.NodetemplatesV1Taint) bool {
+		return lo.SomeBy(tolerations, func(toleration map[string]interface{}) bool {
+			return toleratesTaint(toleration, taint)
+		})
+	})
+}
+
+// nodeTemplateTaints returns taints of the template nodes. Tainted templates without custom taints get the taint with
+// the template name.
+func nodeTemplateTaints(t sdk.NodetemplatesV1NodeTemplate) []sdk.NodetemplatesV1Taint {
+	if !lo.FromPtr(t.ShouldTaint) {
+		return nil
+	}
+	if taints := lo.FromPtr(t.CustomTaints); len(taints) > 0 {
+		return taints
+	}
+
+	return []sdk.NodetemplatesV1Taint{{
+		Key:    lo.ToPtr(nodeTemplateLabelKey),
+		Value:  t.Name,
+		Effect: lo.ToPtr(taintEffectNoSchedule),
+	}}
+}
+
+// toleratesTaint follows Kubernetes toleration matching rules.
+func toleratesTaint(toleration map[string]interface{}, taint sdk.NodetemplatesV1Taint) bool {
+	effect := toleration["effect"].(string)
+	if effect != "" && effect != lo.FromPtr(taint.Effect) {
+		return false
+	}
+
+	key := toleration["key"].(string)
+	if toleration["operator"].(string) == tolerationOperatorExists {
+		return key == "" || key == lo.FromPtr(taint.Key)
+	}
+
+	return key == lo.FromPtr(taint.Key) && toleration["value"].(string) == lo.FromPtr(taint.Value)
+}
@@ -0,0 +1,90 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestNodeTemplateMatchDataSourceRead(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	templates := `{"items": [
+		{"template": {"name": "default-by-castai", "isDefault": true, "shouldTaint": false}},
+		{"template": {"name": "gpu", "shouldTaint": true, "customLabels": {"team": "ml"}}},
+		{"template": {"name": "spot", "shouldTaint": true, "customLabels": {"team": "ml"},
+			"customTaints": [{"key": "spot", "value": "true", "effect": "NoSchedule"}]}}
+	]}`
+
+	tests := map[string]struct {
+		config   map[string]any
+		expected []any
+	}{
+		"pod without selector lands on untainted templates": {
+			config:   map[string]any{},
+			expected: []any{"default-by-castai"},
+		},
+		"selector by template name needs the template toleration": {
+			config: map[string]any{
+				NodeTemplateMatchFieldNodeSelector: map[string]any{nodeTemplateLabelKey: "gpu"},
+			},
+			expected: []any{},
+		},
+		"selector by template name with toleration": {
+			config: map[string]any{
+				NodeTemplateMatchFieldNodeSelector: map[string]any{nodeTemplateLabelKey: "gpu"},
+				NodeTemplateMatchFieldTolerations: []any{
+					map[string]any{"key": nodeTemplateLabelKey, "value": "gpu", "effect": "NoSchedule"},
+				},
+			},
+			expected: []any{"gpu"},
+		},
+		"selector by custom label with exists toleration": {
+			config: map[string]any{
+				NodeTemplateMatchFieldNodeSelector: map[string]any{"team": "ml"},
+				NodeTemplateMatchFieldTolerations: []any{
+					map[string]any{"key": "spot", "operator": "Exists"},
+					map[string]any{"key": nodeTemplateLabelKey, "operator": "Exists"},
+				},
+			},
+			expected: []any{"gpu", "spot"},
+		},
+		"toleration with another effect": {
+			config: map[string]any{
+				NodeTemplateMatchFieldNodeSelector: map[string]any{"team": "ml"},
+				NodeTemplateMatchFieldTolerations: []any{
+					map[string]any{"operator": "Exists", "effect": "NoExecute"},
+				},
+			},
+			expected: []any{},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			mockClient.EXPECT().
+				NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+				Return(testJSONResponse(http.StatusOK, templates), nil)
+
+			dataSource := dataSourceNodeTemplateMatch()
+			tt.config[NodeTemplateMatchFieldClusterID] = clusterId
+			data := schema.TestResourceDataRaw(t, dataSource.Schema, tt.config)
+
+			result := dataSource.ReadContext(context.Background(), data, provider)
+			r.Nil(result)
+			r.Equal(clusterId, data.Id())
+			r.Equal(tt.expected, data.Get(NodeTemplateMatchFieldNodeTemplates))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	NodesFieldClusterID    = "cluster_id"
+	NodesFieldNodeTemplate = "node_template"
+	NodesFieldCapacityType = "capacity_type"
+	NodesFieldZone         = "zone"
+	NodesFieldNodes        = "nodes"
+)
+
+const (
+	NodeCapacityTypeSpot     = "spot"
+	NodeCapacityTypeOnDemand = "on_demand"
+)
+
+const (
+	// nodeManagedByLabelKey is the label CAST AI sets on nodes it provisioned to nodeManagedByCastAI.
+	nodeManagedByLabelKey = "provisioner.cast.ai/managed-by"
+	nodeManagedByCastAI   = "cast.ai"
+)
+
+func dataSourceNodes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNodesRead,
+		Description: "Retrieve nodes of the cluster which are managed by CAST AI. Nodes can be filtered by node template, " +
+			"capacity type and zone.",
+		Schema: map[string]*schema.Schema{
+			NodesFieldClusterID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id.",
+			},
+			NodesFieldNodeTemplate: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "Only return nodes of the node template with the given name.",
+			},
+			NodesFieldCapacityType: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{NodeCapacityTypeSpot, NodeCapacityTypeOnDemand}, false)),
+				Description:      fmt.Sprintf("Only return nodes of the given capacity type. Allowed values: %s, %s.", NodeCapacityTypeSpot, NodeCapacityTypeOnDemand),
+			},
+			NodesFieldZone: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "Only return nodes in the given zone.",
+			},
+			NodesFieldNodes: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "CAST AI node id.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Kubernetes node name.",
+						},
+						"instance_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"zone": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"capacity_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"node_template": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the node template the node was created for, empty for nodes without template.",
+						},
+						"private_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+				Description: "Nodes sorted by name.",
+			},
+		},
+	}
+}
+
+func dataSourceNodesRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	clusterID := data.Get(NodesFieldClusterID).(string)
+	resp, err := listNodes(ctx, client, clusterID)
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(checkErr)
+	}
+
+	template := data.Get(NodesFieldNodeTemplate).(string)
+	capacityType := data.Get(NodesFieldCapacityType).(string)
+	zone := data.Get(NodesFieldZone).(string)
+
+	nodes := make([]map[string]interface{}, 0)
+	for _, n := range lo.FromPtr(resp.JSON200.Items) {
+		labels := lo.FromPtr(n.Labels).AdditionalProperties
+		if labels[nodeManagedByLabelKey] != nodeManagedByCastAI {
+			continue
+		}
+
+		nodeCapacityType := NodeCapacityTypeOnDemand
+		if lo.FromPtr(lo.FromPtr(n.SpotConfig).IsSpot) {
+			nodeCapacityType = NodeCapacityTypeSpot
+		}
+		if template != "" && labels[nodeTemplateLabelKey] != template {
+			continue
+		}
+		if capacityType != "" && nodeCapacityType != capacityType {
+			continue
+		}
+		if zone != "" && lo.FromPtr(n.Zone) != zone {
+			continue
+		}
+
+		nodes = append(nodes, map[string]interface{}{
+			"id":            lo.FromPtr(n.Id),
+			"name":          lo.FromPtr(n.Name),
+			"instance_type": lo.FromPtr(n.InstanceType),
+			"zone":          lo.FromPtr(n.Zone),
+			"capacity_type": nodeCapacityType,
+			"node_template": labels[nodeTemplateLabelKey],
+			"private_ip":    lo.FromPtr(lo.FromPtr(n.Network).PrivateIp),
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i]["name"].(string) < nodes[j]["name"].(string)
+	})
+
+	data.SetId(clusterID)
+	if err := data.Set(NodesFieldNodes, nodes); err != nil {
+		return diag.FromErr(fmt.Errorf("setting nodes: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestNodesDataSourceRead(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	gomock.InOrder(
+		mockClient.EXPECT().
+			ExternalClusterAPIListNodes(gomock.Any(), clusterID, &sdk.ExternalClusterAPIListNodesParams{}).
+			Return(testJSONResponse(http.StatusOK, `{"nextCursor": "2", "items": [
+				{"id": "1", "name": "spot-b", "instanceType": "m5.large", "zone": "eu-central-1a", "spotConfig": {"isSpot": true},
+				 "network": {"privateIp": "10.0.1.2"},
+				 "labels": {"provisioner.cast.ai/managed-by": "cast.ai", "scheduling.cast.ai/node-template": "spot"}},
+				{"id": "2", "name": "on-demand", "instanceType": "m5.large", "zone": "eu-central-1a",
+				 "labels": {"provisioner.cast.ai/managed-by": "cast.ai", "scheduling.cast.ai/node-template": "spot"}},
+				{"id": "3", "name": "unmanaged", "instanceType": "m5.large", "zone": "eu-central-1a", "spotConfig": {"isSpot": true},
+				 "labels": {"scheduling.cast.ai/node-template": "spot"}}
+			]}`), nil),
+		mockClient.EXPECT().
+			ExternalClusterAPIListNodes(gomock.Any(), clusterID, &sdk.ExternalClusterAPIListNodesParams{PageCursor: lo.ToPtr("2")}).
+			Return(testJSONResponse(http.StatusOK, `{"items": [
+				{"id": "4", "name": "spot-a", "instanceType": "c5.xlarge", "zone": "eu-central-1a", "spotConfig": {"isSpot": true},
+				 "labels": {"provisioner.cast.ai/managed-by": "cast.ai", "scheduling.cast.ai/node-template": "spot"}},
+				{"id": "5", "name": "other-zone", "instanceType": "m5.large", "zone": "eu-central-1b", "spotConfig": {"isSpot": true},
+				 "labels": {"provisioner.cast.ai/managed-by": "cast.ai", "scheduling.cast.ai/node-template": "spot"}},
+				{"id": "6", "name": "other-template", "instanceType": "m5.large", "zone": "eu-central-1a", "spotConfig": {"isSpot": true},
+				 "labels": {"provisioner.cast.ai/managed-by": "cast.ai"}}
+			]}`), nil),
+	)
+
+	dataSource := dataSourceNodes()
+	data := schema.TestResourceDataRaw(t, dataSource.Schema, map[string]any{
+		NodesFieldClusterID:    clusterID,
+		NodesFieldNodeTemplate: "spot",
+		NodesFieldCapacityType: NodeCapacityTypeSpot,
+		NodesFieldZone:         "eu-central-1a",
+	})
+
+	result := dataSource.ReadContext(context.Background(), data, provider)
+	r.Nil(result)
+	r.Equal(clusterID, data.Id())
+	r.Equal([]any{
+		map[string]any{"id": "4", "name": "spot-a", "instance_type": "c5.xlarge", "zone": "eu-central-1a", "capacity_type": "spot", "node_template": "spot", "private_ip": ""},
+		map[string]any{"id": "1", "name": "spot-b", "instance_type": "m5.large", "zone": "eu-central-1a", "capacity_type": "spot", "node_template": "spot", "private_ip": "10.0.1.2"},
+	}, data.Get(NodesFieldNodes))
+}
@@ -0,0 +1,373 @@
+package castai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+// fakeAPI is an in-memory double of CAST AI API. It lets acceptance tests exercise CRUD logic of resources without CAST AI
+// credentials or a connected cluster. Only endpoints used by node configurations, node templates and nodes are
+// implemented, other requests fail with 501, so tests relying on them fail loudly instead of passing against made up
+// responses.
+type fakeAPI struct {
+	*httptest.Server
+
+	clusterID     string
+	instanceTypes []map[string]any
+
+	lock           sync.Mutex
+	lastID         int
+	configurations map[string]map[string]any
+	templates      map[string]map[string]any
+	nodes          map[string]map[string]any
+}
+
+// newFakeAPI starts the fake API with a single connected cluster, which has the default node configuration like clusters
+// onboarded to CAST AI. It's closed when the test finishes.
+func newFakeAPI(t *testing.T, clusterID string) *fakeAPI {
+	api := &fakeAPI{
+		clusterID: clusterID,
+		instanceTypes: []map[string]any{
+			{"name": "c5.xlarge", "family": "c5", "architecture": ArchAMD64},
+			{"name": "m5.large", "family": "m5", "architecture": ArchAMD64},
+			{"name": "m6g.large", "family": "m6g", "architecture": ArchARM64},
+		},
+		configurations: map[string]map[string]any{
+			"default": {"id": "default", "name": "default", "version": 1, "default": true, "subnets": []string{}, "tags": map[string]string{}},
+		},
+		templates: map[string]map[string]any{},
+		nodes:     map[string]map[string]any{},
+	}
+	api.Server = httptest.NewServer(http.HandlerFunc(api.serve))
+	t.Cleanup(api.Close)
+
+	return api
+}
+
+// fakeAPIProviderFactories returns provider factories which configure the provider against the fake API instead of the
+// one in provider configuration.
+func fakeAPIProviderFactories(api *fakeAPI) map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		ProviderName: func() (*schema.Provider, error) {
+			p := Provider("v1.0.0")
+			p.ConfigureContextFunc = func(_ context.Context, _ *schema.ResourceData) (interface{}, diag.Diagnostics) {
+				client, err := sdk.NewClientWithResponses(api.URL, sdk.WithHTTPClient(api.Client()))
+				if err != nil {
+					return nil, diag.FromErr(err)
+				}
+
+				return &ProviderConfig{
+					api:       client,
+					apiURL:    api.URL,
+					responses: newResponseCache(listCacheTTL),
+				}, nil
+			}
+			return p, nil
+		},
+	}
+}
+
+// checkDestroyed is a CheckDestroy function which verifies that all resources created by the test are deleted. The
+// default node configuration can't be deleted, the same as in the real API.
+func (a *fakeAPI) checkDestroyed(_ *terraform.State) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for name := range a.templates {
+		return fmt.Errorf("node template %q still exists", name)
+	}
+	for id := range a.nodes {
+		return fmt.Errorf("node %s still exists", id)
+	}
+	for id, c := range a.configurations {
+		if c["default"] != true {
+			return fmt.Errorf("node configuration %s still exists", id)
+		}
+	}
+
+	return nil
+}
+
+func (a *fakeAPI) serve(w http.ResponseWriter, req *http.Request) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 5 && path[2] == "external-clusters" && path[3] == "operations":
+		// Operations are done as soon as they are started.
+		a.write(w, http.StatusOK, map[string]any{"id": path[4], "done": true})
+	case len(path) >= 5 && path[2] == "external-clusters" && path[4] == "nodes":
+		if path[3] != a.clusterID {
+			a.write(w, http.StatusNotFound, map[string]any{"message": "cluster not found"})
+			return
+		}
+		a.serveNodes(w, req, path[5:])
+	case len(path) == 4 && path[2] == "external-clusters":
+		if path[3] != a.clusterID {
+			a.write(w, http.StatusNotFound, map[string]any{"message": "cluster not found"})
+			return
+		}
+		a.write(w, http.StatusOK, map[string]any{"id": a.clusterID, "name": "fake", "status": sdk.ClusterStatusReady})
+	case len(path) >= 5 && path[2] == "clusters":
+		if path[3] != a.clusterID {
+			a.write(w, http.StatusNotFound, map[string]any{"message": "cluster not found"})
+			return
+		}
+		switch path[4] {
+		case "filter-instance-types":
+			a.write(w, http.StatusOK, map[string]any{"availableInstanceTypes": a.instanceTypes})
+		case "node-configurations":
+			a.serveNodeConfigurations(w, req, path[5:])
+		case "node-templates":
+			a.serveNodeTemplates(w, req, path[5:])
+		default:
+			a.write(w, http.StatusNotImplemented, map[string]any{"message": "not implemented"})
+		}
+	default:
+		a.write(w, http.StatusNotImplemented, map[string]any{"message": "not implemented"})
+	}
+}
+
+func (a *fakeAPI) serveNodeConfigurations(w http.ResponseWriter, req *http.Request, path []string) {
+	if len(path) == 0 {
+		switch req.Method {
+		case http.MethodGet:
+			items := make([]map[string]any, 0, len(a.configurations))
+			for _, c := range a.configurations {
+				items = append(items, c)
+			}
+			a.write(w, http.StatusOK, map[string]any{"items": items})
+		case http.MethodPost:
+			c, ok := a.read(w, req)
+			if !ok {
+				return
+			}
+			a.lastID++
+			c["id"] = fmt.Sprintf("00000000-0000-0000-0000-%012d", a.lastID)
+			c["version"] = 1
+			c["default"] = false
+			c["createdAt"] = time.Now().UTC().Format(time.RFC3339)
+			withDefaultTags(c)
+			a.configurations[c["id"].(string)] = c
+			a.write(w, http.StatusOK, c)
+		default:
+			a.write(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+		}
+		return
+	}
+
+	current, ok := a.configurations[path[0]]
+	if !ok {
+		a.write(w, http.StatusNotFound, map[string]any{"message": "node configuration not found"})
+		return
+	}
+
+	switch {
+	case len(path) == 2 && path[1] == "default" && req.Method == http.MethodPost:
+		for _, c := range a.configurations {
+			c["default"] = false
+		}
+		current["default"] = true
+		a.write(w, http.StatusOK, current)
+	case len(path) == 1 && req.Method == http.MethodGet:
+		a.write(w, http.StatusOK, current)
+	case len(path) == 1 && req.Method == http.MethodPost:
+		c, ok := a.read(w, req)
+		if !ok {
+			return
+		}
+		// Update request doesn't contain read-only fields, they are kept from the current configuration.
+		for _, key := range []string{"id", "name", "default", "createdAt"} {
+			c[key] = current[key]
+		}
+		c["version"] = current["version"].(int) + 1
+		withDefaultTags(c)
+		a.configurations[path[0]] = c
+		a.write(w, http.StatusOK, c)
+	case len(path) == 1 && req.Method == http.MethodDelete:
+		delete(a.configurations, path[0])
+		a.write(w, http.StatusOK, map[string]any{})
+	default:
+		a.write(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+	}
+}
+
+func (a *fakeAPI) serveNodeTemplates(w http.ResponseWriter, req *http.Request, path []string) {
+	if len(path) == 0 {
+		switch req.Method {
+		case http.MethodGet:
+			items := make([]map[string]any, 0, len(a.templates))
+			for _, t := range a.templates {
+				items = append(items, map[string]any{"template": t})
+			}
+			a.write(w, http.StatusOK, map[string]any{"items": items})
+		case http.MethodPost:
+			t, ok := a.read(w, req)
+			if !ok {
+				return
+			}
+			name, _ := t["name"].(string)
+			if _, exists := a.templates[name]; exists {
+				a.write(w, http.StatusConflict, map[string]any{"message": "node template already exists"})
+				return
+			}
+			t["version"] = "1"
+			withTemplateDefaults(t)
+			a.templates[name] = t
+			a.write(w, http.StatusOK, t)
+		default:
+			a.write(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+		}
+		return
+	}
+
+	current, ok := a.templates[path[0]]
+	if !ok || len(path) != 1 {
+		a.write(w, http.StatusNotFound, map[string]any{"message": "node template not found"})
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPut:
+		t, ok := a.read(w, req)
+		if !ok {
+			return
+		}
+		version, _ := strconv.Atoi(current["version"].(string))
+		t["name"] = current["name"]
+		t["version"] = strconv.Itoa(version + 1)
+		withTemplateDefaults(t)
+		a.templates[path[0]] = t
+		a.write(w, http.StatusOK, t)
+	case http.MethodDelete:
+		delete(a.templates, path[0])
+		a.write(w, http.StatusOK, map[string]any{})
+	default:
+		a.write(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+	}
+}
+
+func (a *fakeAPI) serveNodes(w http.ResponseWriter, req *http.Request, path []string) {
+	if len(path) == 0 {
+		if req.Method != http.MethodPost {
+			a.write(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+			return
+		}
+		body, ok := a.read(w, req)
+		if !ok {
+			return
+		}
+		a.lastID++
+		id := fmt.Sprintf("00000000-0000-0000-0000-%012d", a.lastID)
+		node := map[string]any{
+			"id":                  id,
+			"name":                fmt.Sprintf("ip-10-0-0-%d", a.lastID),
+			"instanceType":        body["instanceType"],
+			"instanceId":          fmt.Sprintf("i-%012d", a.lastID),
+			"zone":                "eu-central-1a",
+			"nodeConfigurationId": "default",
+			"spotConfig":          map[string]any{"isSpot": false},
+			"network":             map[string]any{"privateIp": fmt.Sprintf("10.0.0.%d", a.lastID)},
+		}
+		for from, to := range map[string]string{"zone": "zone", "configurationId": "nodeConfigurationId", "spotConfig": "spotConfig"} {
+			if v, ok := body[from]; ok {
+				node[to] = v
+			}
+		}
+		a.nodes[id] = node
+		a.write(w, http.StatusOK, map[string]any{"nodeId": id, "operationId": "add-" + id})
+		return
+	}
+
+	node, ok := a.nodes[path[0]]
+	if !ok || len(path) != 1 {
+		a.write(w, http.StatusNotFound, map[string]any{"message": "node not found"})
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		a.write(w, http.StatusOK, node)
+	case http.MethodDelete:
+		delete(a.nodes, path[0])
+		a.write(w, http.StatusOK, map[string]any{"operationId": "delete-" + path[0]})
+	default:
+		a.write(w, http.StatusMethodNotAllowed, map[string]any{"message": "method not allowed"})
+	}
+}
+
+// withDefaultTags sets empty tags of node configuration when they aren't given, the API always returns them.
+func withDefaultTags(c map[string]any) {
+	if _, ok := c["tags"]; !ok {
+		c["tags"] = map[string]string{}
+	}
+}
+
+// withTemplateDefaults sets rebalancing config of node template when it isn't given, the API always returns it.
+func withTemplateDefaults(t map[string]any) {
+	if _, ok := t["rebalancingConfig"]; !ok {
+		t["rebalancingConfig"] = map[string]any{"minNodes": 0}
+	}
+}
+
+func (a *fakeAPI) read(w http.ResponseWriter, req *http.Request) (map[string]any, bool) {
+	body := map[string]any{}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		a.write(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+		return nil, false
+	}
+
+	return body, true
+}
+
+func (a *fakeAPI) write(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestFakeAPI(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	api := newFakeAPI(t, clusterID)
+
+	provider, err := fakeAPIProviderFactories(api)[ProviderName]()
+	r.NoError(err)
+	meta, diags := provider.ConfigureContextFunc(ctx, nil)
+	r.Nil(diags)
+
+	resource := resourceNodeConfiguration()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID:                clusterID,
+		FieldNodeConfigurationName:    "test",
+		FieldNodeConfigurationSubnets: []interface{}{"subnet-1"},
+	})
+
+	r.Nil(resource.CreateContext(ctx, data, meta))
+	r.NotEmpty(data.Id())
+	r.Equal(0, data.Get(FieldNodeConfigurationDiskCpuRatio))
+	r.Error(api.checkDestroyed(nil))
+
+	r.Nil(resource.DeleteContext(ctx, data, meta))
+	r.NoError(api.checkDestroyed(nil))
+
+	r.Nil(resource.ReadContext(ctx, data, meta))
+	r.Empty(data.Id())
+}
@@ -0,0 +1,50 @@
+package castai
+
+import (
+	"log"
+	"sync"
+)
+
+// clusterLocks serializes changes of cluster level documents (e.g. autoscaler policies) which are read, modified and
+// written back by several resources. Terraform applies independent resources concurrently, so without a lock one
+// resource could overwrite changes made by another one during the same apply.
+var clusterLocks = newMutexKV()
+
+// mutexKV holds a mutex per key, created on first use.
+type mutexKV struct {
+	lock  sync.Mutex
+	store map[string]*sync.Mutex
+}
+
+func newMutexKV() *mutexKV {
+	return &mutexKV{
+		store: make(map[string]*sync.Mutex),
+	}
+}
+
+// Lock blocks until the key is not locked by other callers.
+func (m *mutexKV) Lock(key string) {
+	log.Printf("[DEBUG] Locking %q", key)
+	m.get(key).Lock()
+	log.Printf("[DEBUG] Locked %q", key)
+}
+
+// Unlock releases the key locked by Lock.
+func (m *mutexKV) Unlock(key string) {
+	log.Printf("[DEBUG] Unlocking %q", key)
+	m.get(key).Unlock()
+	log.Printf("[DEBUG] Unlocked %q", key)
+}
+
+func (m *mutexKV) get(key string) *sync.Mutex {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	mutex, ok := m.store[key]
+	if !ok {
+		mutex = &sync.Mutex{}
+		m.store[key] = mutex
+	}
+
+	return mutex
+}
@@ -0,0 +1,42 @@
+package castai
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutexKV(t *testing.T) {
+	t.Run("should serialize access for the same key", func(t *testing.T) {
+		r := require.New(t)
+		m := newMutexKV()
+
+		counter := 0
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.Lock("cluster")
+				defer m.Unlock("cluster")
+
+				current := counter
+				counter = current + 1
+			}()
+		}
+		wg.Wait()
+
+		r.Equal(50, counter)
+	})
+
+	t.Run("should not block other keys", func(t *testing.T) {
+		m := newMutexKV()
+
+		m.Lock("cluster-1")
+		defer m.Unlock("cluster-1")
+
+		m.Lock("cluster-2")
+		m.Unlock("cluster-2")
+	})
+}
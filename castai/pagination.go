@@ -0,0 +1,81 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	listPageMaxRetries    = 3
+	listPageRetryInterval = 500 * time.Millisecond
+)
+
+// listNodes fetches all pages of the cluster node list, so callers don't miss nodes beyond the first page. Each page
+// request is retried on transient errors. Unsuccessful responses are returned as they are, so callers can handle them
+// like responses of a single request.
+func listNodes(ctx context.Context, client *sdk.ClientWithResponses, clusterID string) (*sdk.ExternalClusterAPIListNodesResponse, error) {
+	var first *sdk.ExternalClusterAPIListNodesResponse
+	seen := map[string]bool{}
+
+	for params := (&sdk.ExternalClusterAPIListNodesParams{}); ; {
+		page, err := fetchWithRetry(ctx, func() (*sdk.ExternalClusterAPIListNodesResponse, error) {
+			return client.ExternalClusterAPIListNodesWithResponse(ctx, clusterID, params)
+		})
+		if err != nil || page.StatusCode() != http.StatusOK {
+			return page, err
+		}
+
+		if first == nil {
+			first = page
+		} else {
+			first.JSON200.Items = lo.ToPtr(append(lo.FromPtr(first.JSON200.Items), lo.FromPtr(page.JSON200.Items)...))
+		}
+
+		cursor := lo.FromPtr(page.JSON200.NextCursor)
+		if cursor == "" {
+			return first, nil
+		}
+		if seen[cursor] {
+			return nil, fmt.Errorf("list nodes returned page cursor %q more than once", cursor)
+		}
+		seen[cursor] = true
+		params = &sdk.ExternalClusterAPIListNodesParams{PageCursor: lo.ToPtr(cursor)}
+	}
+}
+
+// fetchWithRetry retries the list request on network errors, throttling and server errors.
+func fetchWithRetry[T sdk.Response](ctx context.Context, fetch func() (T, error)) (T, error) {
+	var (
+		page     T
+		fetchErr error
+	)
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = listPageRetryInterval
+	_ = backoff.Retry(func() error {
+		page, fetchErr = fetch()
+		if err := fetchErr; err != nil {
+			if ctx.Err() != nil {
+				return backoff.Permanent(err)
+			}
+			log.Printf("[WARN] Listing failed, retrying: %v", err)
+			return err
+		}
+		if code := page.StatusCode(); code == http.StatusTooManyRequests || code >= http.StatusInternalServerError {
+			log.Printf("[WARN] Listing failed with status %d, retrying", code)
+			return fmt.Errorf("unexpected status code %d", code)
+		}
+		return nil
+	}, backoff.WithContext(backoff.WithMaxRetries(b, listPageMaxRetries), ctx))
+
+	// Server errors which persist after retries are returned as responses, callers report them with the response body.
+	return page, fetchErr
+}
@@ -0,0 +1,117 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestListNodes(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	t.Run("lists all pages", func(t *testing.T) {
+		r := require.New(t)
+		mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+		client := &sdk.ClientWithResponses{ClientInterface: mockClient}
+
+		gomock.InOrder(
+			mockClient.EXPECT().
+				ExternalClusterAPIListNodes(gomock.Any(), clusterId, &sdk.ExternalClusterAPIListNodesParams{}).
+				Return(testJSONResponse(http.StatusOK, `{"items": [{"name": "a"}], "nextCursor": "b"}`), nil),
+			mockClient.EXPECT().
+				ExternalClusterAPIListNodes(gomock.Any(), clusterId, &sdk.ExternalClusterAPIListNodesParams{PageCursor: lo.ToPtr("b")}).
+				Return(testJSONResponse(http.StatusOK, `{"items": [{"name": "b"}], "nextCursor": "c"}`), nil),
+			mockClient.EXPECT().
+				ExternalClusterAPIListNodes(gomock.Any(), clusterId, &sdk.ExternalClusterAPIListNodesParams{PageCursor: lo.ToPtr("c")}).
+				Return(testJSONResponse(http.StatusOK, `{"items": [{"name": "c"}]}`), nil),
+		)
+
+		resp, err := listNodes(context.Background(), client, clusterId)
+		r.NoError(sdk.CheckOKResponse(resp, err))
+		names := lo.Map(*resp.JSON200.Items, func(n sdk.ExternalclusterV1Node, _ int) string {
+			return lo.FromPtr(n.Name)
+		})
+		r.Equal([]string{"a", "b", "c"}, names)
+	})
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		r := require.New(t)
+		mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+		client := &sdk.ClientWithResponses{ClientInterface: mockClient}
+
+		gomock.InOrder(
+			mockClient.EXPECT().
+				ExternalClusterAPIListNodes(gomock.Any(), clusterId, &sdk.ExternalClusterAPIListNodesParams{}).
+				Return(testJSONResponse(http.StatusServiceUnavailable, `{"message": "unavailable"}`), nil),
+			mockClient.EXPECT().
+				ExternalClusterAPIListNodes(gomock.Any(), clusterId, &sdk.ExternalClusterAPIListNodesParams{}).
+				Return(testJSONResponse(http.StatusOK, `{"items": [{"id": "1"}], "nextCursor": "2"}`), nil),
+			mockClient.EXPECT().
+				ExternalClusterAPIListNodes(gomock.Any(), clusterId, &sdk.ExternalClusterAPIListNodesParams{PageCursor: lo.ToPtr("2")}).
+				Return(testJSONResponse(http.StatusTooManyRequests, `{"message": "slow down"}`), nil),
+			mockClient.EXPECT().
+				ExternalClusterAPIListNodes(gomock.Any(), clusterId, &sdk.ExternalClusterAPIListNodesParams{PageCursor: lo.ToPtr("2")}).
+				Return(testJSONResponse(http.StatusOK, `{"items": [{"id": "2"}]}`), nil),
+		)
+
+		resp, err := listNodes(context.Background(), client, clusterId)
+		r.NoError(sdk.CheckOKResponse(resp, err))
+		r.Len(*resp.JSON200.Items, 2)
+	})
+
+	t.Run("doesn't retry client errors", func(t *testing.T) {
+		r := require.New(t)
+		mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+		client := &sdk.ClientWithResponses{ClientInterface: mockClient}
+
+		mockClient.EXPECT().
+			ExternalClusterAPIListNodes(gomock.Any(), clusterId, gomock.Any()).
+			Return(testJSONResponse(http.StatusNotFound, `{"message": "not found"}`), nil)
+
+		resp, err := listNodes(context.Background(), client, clusterId)
+		r.NoError(err)
+		r.Equal(http.StatusNotFound, resp.StatusCode())
+	})
+
+	t.Run("fails on repeated cursor", func(t *testing.T) {
+		r := require.New(t)
+		mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+		client := &sdk.ClientWithResponses{ClientInterface: mockClient}
+
+		mockClient.EXPECT().
+			ExternalClusterAPIListNodes(gomock.Any(), clusterId, gomock.Any()).
+			DoAndReturn(func(context.Context, string, *sdk.ExternalClusterAPIListNodesParams, ...sdk.RequestEditorFn) (*http.Response, error) {
+				return testJSONResponse(http.StatusOK, `{"items": [], "nextCursor": "a"}`), nil
+			}).Times(2)
+
+		_, err := listNodes(context.Background(), client, clusterId)
+		r.EqualError(err, `list nodes returned page cursor "a" more than once`)
+	})
+}
+
+func TestListNodeConfigurationsRetriesTransientErrors(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	gomock.InOrder(
+		mockClient.EXPECT().
+			NodeConfigurationAPIListConfigurations(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusServiceUnavailable, `{"message": "unavailable"}`), nil),
+		mockClient.EXPECT().
+			NodeConfigurationAPIListConfigurations(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, `{"items": [{"id": "1"}, {"id": "2"}]}`), nil),
+	)
+
+	resp, err := listNodeConfigurations(context.Background(), provider, clusterId)
+	r.NoError(sdk.CheckOKResponse(resp, err))
+	r.Len(*resp.JSON200.Items, 2)
+}
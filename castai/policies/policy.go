@@ -10,11 +10,17 @@ import (
 var (
 	//go:embed iam-policy.json
 	IAMPolicy string
+	//go:embed iam-policy-scoped.json
+	ScopedIAMPolicy string
 	//go:embed user-policy.json
 	UserPolicy string
 )
 
-func GetIAMPolicy(accountNumber string) (string, error) {
+// DefaultIAMPath is the root IAM path, which includes roles and instance profiles of all paths.
+const DefaultIAMPath = "/"
+
+// GetIAMPolicy returns the IAM policy of CAST AI. Roles it can pass to EC2 are limited to the IAM path.
+func GetIAMPolicy(accountNumber, iamPath string) (string, error) {
 	tmpl, err := template.New("json").Parse(IAMPolicy)
 	if err != nil {
 		return "", fmt.Errorf("parsing template: %w", err)
@@ -22,12 +28,42 @@ func GetIAMPolicy(accountNumber string) (string, error) {
 
 	type tmplValues struct {
 		AccountNumber string
+		Path          string
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, tmplValues{
+		AccountNumber: accountNumber,
+		Path:          iamPath,
+	}); err != nil {
+		return "", fmt.Errorf("interpolating template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// GetScopedIAMPolicy returns a variant of the IAM policy for accounts where broad permissions are denied by SCPs. Changes
+// to existing IAM and EC2 resources are only allowed for resources tagged with kubernetes.io/cluster/<name>, IAM roles and
+// instance profiles are also limited to the IAM path.
+func GetScopedIAMPolicy(accountNumber, clusterName, iamPath string) (string, error) {
+	tmpl, err := template.New("json").Parse(ScopedIAMPolicy)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+
+	type tmplValues struct {
+		AccountNumber string
+		ClusterName   string
+		Path          string
 	}
 
 	var buf bytes.Buffer
 
 	if err := tmpl.Execute(&buf, tmplValues{
 		AccountNumber: accountNumber,
+		ClusterName:   clusterName,
+		Path:          iamPath,
 	}); err != nil {
 		return "", fmt.Errorf("interpolating template: %w", err)
 	}
@@ -1,13 +1,14 @@
 package policies
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 )
 
 func TestPolicies(t *testing.T) {
 	t.Run("IAM policy", func(t *testing.T) {
-		iamPolicy, err := GetIAMPolicy("testaccount")
+		iamPolicy, err := GetIAMPolicy("testaccount", DefaultIAMPath)
 		if err != nil {
 			t.Fatalf("couldn't generate IAM policy")
 		}
@@ -23,6 +24,58 @@ func TestPolicies(t *testing.T) {
 		}
 	})
 
+	t.Run("IAM policy with path", func(t *testing.T) {
+		iamPolicy, err := GetIAMPolicy("testaccount", "/castai/")
+		if err != nil {
+			t.Fatalf("couldn't generate IAM policy")
+		}
+
+		if !strings.Contains(iamPolicy, `"arn:aws:iam::*:role/castai/*"`) {
+			t.Fatalf("generated IAM policy does not limit roles to the path")
+		}
+	})
+
+	t.Run("Scoped IAM policy", func(t *testing.T) {
+		iamPolicy, err := GetScopedIAMPolicy("testaccount", "clustername", DefaultIAMPath)
+		if err != nil {
+			t.Fatalf("couldn't generate scoped IAM policy")
+		}
+
+		if !json.Valid([]byte(iamPolicy)) {
+			t.Fatalf("generated scoped IAM policy is not valid JSON")
+		}
+
+		condition := "aws:ResourceTag/kubernetes.io/cluster/clustername"
+
+		if !strings.Contains(iamPolicy, condition) {
+			t.Fatalf("generated scoped IAM policy does not contain cluster tag condition")
+		}
+
+		if strings.Contains(iamPolicy, `"Resource": "*"`) {
+			t.Fatalf("generated scoped IAM policy allows all resources")
+		}
+
+		if strings.Contains(iamPolicy, ".AccountNumber") || strings.Contains(iamPolicy, ".ClusterName") || strings.Contains(iamPolicy, ".Path") {
+			t.Fatalf("Incorrectly formatted template")
+		}
+	})
+
+	t.Run("Scoped IAM policy with path", func(t *testing.T) {
+		iamPolicy, err := GetScopedIAMPolicy("testaccount", "clustername", "/castai/")
+		if err != nil {
+			t.Fatalf("couldn't generate scoped IAM policy")
+		}
+
+		for _, resource := range []string{
+			`"arn:aws:iam::testaccount:role/castai/*"`,
+			`"arn:aws:iam::testaccount:instance-profile/castai/*"`,
+		} {
+			if !strings.Contains(iamPolicy, resource) {
+				t.Fatalf("generated scoped IAM policy does not limit %s to the path", resource)
+			}
+		}
+	})
+
 	t.Run("User policy", func(t *testing.T) {
 		userpolicy, err := GetUserInlinePolicy("clustername", "testarn", "testvpc")
 		if err != nil || userpolicy == "" {
@@ -3,16 +3,26 @@ package castai
 import (
 	"context"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
 
 	"github.com/castai/terraform-provider-castai/castai/sdk"
 )
 
 type ProviderConfig struct {
-	api *sdk.ClientWithResponses
+	api    *sdk.ClientWithResponses
+	apiURL string
+
+	// responses caches list responses of cluster level endpoints, see listCacheKey.
+	responses *responseCache
+
+	// offline is set when the provider plans without CAST AI API, api is nil then.
+	offline bool
 }
 
 func Provider(version string) *schema.Provider {
@@ -31,6 +41,41 @@ func Provider(version string) *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("CASTAI_API_TOKEN", nil),
 				Description: "The token used to connect to CAST AI API.",
 			},
+			"http_timeout_seconds": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("CASTAI_HTTP_TIMEOUT_SECONDS", int(sdk.DefaultHTTPTimeout.Seconds())),
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Time limit in seconds of a single request to CAST AI API.",
+			},
+			"requests_per_second": {
+				Type:             schema.TypeFloat,
+				Optional:         true,
+				DefaultFunc:      schema.EnvDefaultFunc("CASTAI_REQUESTS_PER_SECOND", 0),
+				ValidateDiagFunc: validation.ToDiagFunc(validation.FloatAtLeast(0)),
+				Description: "Maximum number of requests per second to CAST AI API, `0` means no limit. Limit requests of " +
+					"large workspaces to avoid hitting organization rate limits.",
+			},
+			"tls_insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CASTAI_TLS_INSECURE_SKIP_VERIFY", false),
+				Description: "Skip verification of CAST AI API TLS certificate. Use `ca_certificate_pem` instead where possible.",
+			},
+			"ca_certificate_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CASTAI_CA_CERTIFICATE_PEM", ""),
+				Description: "PEM encoded CA certificates to trust in addition to the system ones, e.g. of a TLS-intercepting proxy.",
+			},
+			"offline": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CASTAI_OFFLINE", false),
+				Description: "Plan without connecting to CAST AI API, e.g. to validate configurations in air-gapped CI. Refresh of " +
+					"resources is skipped with a warning, so plans only show changes of the configuration. Reading data " +
+					"sources which use CAST AI API, importing and applying changes fail.",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -38,39 +83,172 @@ func Provider(version string) *schema.Provider {
 			"castai_eks_clusterid":              resourceEKSClusterID(),
 			"castai_gke_cluster":                resourceGKECluster(),
 			"castai_aks_cluster":                resourceAKSCluster(),
+			"castai_kops_cluster":               resourceKOPSCluster(),
+			"castai_rosa_cluster":               resourceROSACluster(),
 			"castai_autoscaler":                 resourceAutoscaler(),
 			"castai_node_template":              resourceNodeTemplate(),
+			"castai_node_templates":             resourceNodeTemplates(),
 			"castai_node_configuration":         resourceNodeConfiguration(),
 			"castai_node_configuration_default": resourceNodeConfigurationDefault(),
+			"castai_scaling_policy":             resourceScalingPolicy(),
+			"castai_api_token":                  resourceAPIToken(),
+			"castai_cluster_settings":           resourceClusterSettings(),
+			"castai_node":                       resourceNode(),
 			// TODO: remove with next major release.
 			"castai_cluster_token": resourceClusterToken(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"castai_eks_settings":      dataSourceEKSSettings(),
-			"castai_eks_user_arn":      dataSourceEKSClusterUserARN(),
-			"castai_gke_user_policies": dataSourceGKEPolicies(),
+			"castai_eks_settings":        dataSourceEKSSettings(),
+			"castai_eks_user_arn":        dataSourceEKSClusterUserARN(),
+			"castai_gke_cluster_id":      dataSourceGKEClusterID(),
+			"castai_gke_user_policies":   dataSourceGKEPolicies(),
+			"castai_instance_types":      dataSourceInstanceTypes(),
+			"castai_node_template_match": dataSourceNodeTemplateMatch(),
+			"castai_nodes":               dataSourceNodes(),
 			// TODO: remove with next major release.
 			"castai_eks_clusterid": dataSourceEKSClusterID(),
 		},
 
-		ConfigureContextFunc: providerConfigure(version),
+		ProviderMetaSchema: map[string]*schema.Schema{
+			"module_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the module using the provider. It's sent to CAST AI API with every request of the module's resources.",
+			},
+		},
+	}
+	p.ConfigureContextFunc = providerConfigure(p, version)
+
+	for name, r := range p.ResourcesMap {
+		r.CreateContext = withModuleName(requireOnline(r.CreateContext, "Creating "+name))
+		r.ReadContext = withModuleName(skipReadOffline(r.ReadContext, name))
+		r.UpdateContext = withModuleName(requireOnline(r.UpdateContext, "Updating "+name))
+		r.DeleteContext = withModuleName(requireOnline(r.DeleteContext, "Deleting "+name))
+		if r.Importer != nil && r.Importer.StateContext != nil {
+			r.Importer.StateContext = requireOnlineImport(r.Importer.StateContext, name)
+		}
+	}
+	for name, r := range p.DataSourcesMap {
+		if !localDataSources[name] {
+			r.ReadContext = requireOnline(r.ReadContext, "Reading data source "+name)
+		}
+		r.ReadContext = withModuleName(r.ReadContext)
 	}
 
 	return p
 }
 
-func providerConfigure(version string) schema.ConfigureContextFunc {
+// localDataSources are data sources which don't use CAST AI API, so they can be read in offline mode.
+var localDataSources = map[string]bool{
+	"castai_eks_settings":      true,
+	"castai_gke_user_policies": true,
+}
+
+// providerMeta is the provider_meta block which modules can set for their resources.
+type providerMeta struct {
+	ModuleName *string `cty:"module_name"`
+}
+
+// withModuleName passes module name from provider_meta to API requests made by f.
+func withModuleName[F ~func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics](f F) F {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		var m providerMeta
+		if err := d.GetProviderMeta(&m); err != nil {
+			log.Printf("[WARN] Reading provider_meta: %v", err)
+		}
+		if name := lo.FromPtr(m.ModuleName); name != "" {
+			ctx = sdk.WithModuleName(ctx, name)
+		}
+
+		return f(ctx, d, meta)
+	}
+}
+
+// isOffline returns true when the provider plans without CAST AI API.
+func isOffline(meta interface{}) bool {
+	config, ok := meta.(*ProviderConfig)
+	return ok && config.offline
+}
+
+// skipReadOffline skips refresh of the resource in offline mode, its state is kept as it is.
+func skipReadOffline(f schema.ReadContextFunc, resource string) schema.ReadContextFunc {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if isOffline(meta) {
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  "Refresh skipped in offline mode",
+				Detail:   fmt.Sprintf("State of %s %q wasn't refreshed, changes made outside of Terraform aren't shown.", resource, d.Id()),
+			}}
+		}
+
+		return f(ctx, d, meta)
+	}
+}
+
+// requireOnline fails the operation in offline mode, since it can't be done without CAST AI API.
+func requireOnline[F ~func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics](f F, operation string) F {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		if isOffline(meta) {
+			return diag.Errorf("%s requires CAST AI API, it isn't possible in offline mode", operation)
+		}
+
+		return f(ctx, d, meta)
+	}
+}
+
+// requireOnlineImport fails import in offline mode, since imported resources are read from CAST AI API.
+func requireOnlineImport(f schema.StateContextFunc, resource string) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		if isOffline(meta) {
+			return nil, fmt.Errorf("importing %s requires CAST AI API, it isn't possible in offline mode", resource)
+		}
+
+		return f(ctx, d, meta)
+	}
+}
+
+func providerConfigure(p *schema.Provider, version string) schema.ConfigureContextFunc {
 	return func(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
 		apiURL := data.Get("api_url").(string)
+		if data.Get("offline").(bool) {
+			log.Printf("[WARN] Provider is in offline mode, CAST AI API isn't used")
+			return &ProviderConfig{apiURL: apiURL, offline: true}, nil
+		}
+
 		apiToken := data.Get("api_token").(string)
+		timeout := time.Duration(data.Get("http_timeout_seconds").(int)) * time.Second
+		insecureSkipVerify := data.Get("tls_insecure_skip_verify").(bool)
+		if insecureSkipVerify {
+			log.Printf("[WARN] TLS certificate verification of CAST AI API is disabled")
+		}
 
-		agent := fmt.Sprintf("castai-terraform-provider/%v", version)
-		client, err := sdk.CreateClient(apiURL, apiToken, agent)
+		// Terraform and SDK versions are known only once the provider is configured.
+		agent := fmt.Sprintf("castai-terraform-provider/%v %s", version, p.UserAgent("", ""))
+		client, err := sdk.CreateClient(apiURL, apiToken, agent, timeout,
+			sdk.WithTLS(insecureSkipVerify, data.Get("ca_certificate_pem").(string)),
+			sdk.WithRateLimit(data.Get("requests_per_second").(float64)),
+		)
 		if err != nil {
 			return nil, diag.FromErr(err)
 		}
 
-		return &ProviderConfig{api: client}, nil
+		return &ProviderConfig{
+			api:       client,
+			apiURL:    apiURL,
+			responses: newResponseCache(listCacheTTL),
+		}, nil
 	}
 }
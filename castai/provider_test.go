@@ -2,18 +2,30 @@ package castai
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
 )
 
 const (
 	ProviderName   = "castai"
 	ResourcePrefix = "tf-acc-test"
+
+	// testAccAPIURL is used when CASTAI_API_URL is not set.
+	testAccAPIURL = "https://api.dev-master.cast.ai"
 )
 
 var (
@@ -43,7 +55,7 @@ func testAccPreCheck(t *testing.T) {
 	testAccProviderConfigure.Do(func() {
 		if os.Getenv("CASTAI_API_URL") == "" {
 			// Run acceptance on dev by default if not set.
-			os.Setenv("CASTAI_API_URL", "https://api.dev-master.cast.ai")
+			os.Setenv("CASTAI_API_URL", testAccAPIURL)
 		}
 
 		if v := os.Getenv("CASTAI_API_TOKEN"); v == "" {
@@ -64,3 +76,108 @@ func ConfigCompose(config ...string) string {
 	}
 	return str.String()
 }
+
+func TestProviderHTTPTimeoutFromEnv(t *testing.T) {
+	t.Setenv("CASTAI_HTTP_TIMEOUT_SECONDS", "300")
+
+	data := schema.TestResourceDataRaw(t, Provider("v1.0.0").Schema, map[string]interface{}{
+		"api_token": "token",
+	})
+	if v := data.Get("http_timeout_seconds").(int); v != 300 {
+		t.Fatalf("expected timeout from environment, got %d", v)
+	}
+}
+
+func TestProviderModuleNameUserAgent(t *testing.T) {
+	r := require.New(t)
+	id := "4e4cd9eb-a9ab-4a9c-8f2c-5d7e3b1c2a10"
+
+	userAgents := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/v1/auth/tokens/"+id {
+			userAgents <- req.UserAgent()
+			_, _ = w.Write([]byte(`{"id": "` + id + `", "name": "ci", "active": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client, err := sdk.CreateClient(srv.URL, "token", "castai-terraform-provider/v1.0.0", sdk.DefaultHTTPTimeout)
+	r.NoError(err)
+
+	p := Provider("v1.0.0")
+	p.SetMeta(&ProviderConfig{api: client, apiURL: srv.URL})
+
+	stateType := p.ResourcesMap["castai_api_token"].CoreConfigSchema().ImpliedType()
+	attrs := map[string]cty.Value{}
+	for name, attrType := range stateType.AttributeTypes() {
+		attrs[name] = cty.NullVal(attrType)
+	}
+	attrs["id"] = cty.StringVal(id)
+	attrs[FieldAPITokenName] = cty.StringVal("ci")
+	state, err := msgpack.Marshal(cty.ObjectVal(attrs), stateType)
+	r.NoError(err)
+
+	metaType := schema.InternalMap(p.ProviderMetaSchema).CoreConfigSchema().ImpliedType()
+	providerMeta, err := msgpack.Marshal(cty.ObjectVal(map[string]cty.Value{
+		"module_name": cty.StringVal("eks-cluster"),
+	}), metaType)
+	r.NoError(err)
+
+	resp, err := schema.NewGRPCProviderServer(p).ReadResource(context.Background(), &tfprotov5.ReadResourceRequest{
+		TypeName:     "castai_api_token",
+		CurrentState: &tfprotov5.DynamicValue{MsgPack: state},
+		ProviderMeta: &tfprotov5.DynamicValue{MsgPack: providerMeta},
+	})
+	r.NoError(err)
+	r.Empty(resp.Diagnostics)
+	r.Equal("castai-terraform-provider/v1.0.0 module/eks-cluster", <-userAgents)
+}
+
+func TestProviderOfflineMode(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	t.Setenv("CASTAI_OFFLINE", "true")
+
+	p := Provider("v1.0.0")
+	data := schema.TestResourceDataRaw(t, p.Schema, map[string]interface{}{
+		"api_token": "token",
+	})
+	meta, diags := p.ConfigureContextFunc(ctx, data)
+	r.Nil(diags)
+	r.True(isOffline(meta))
+
+	resource := p.ResourcesMap["castai_node_template"]
+	state := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID:        "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		FieldNodeTemplateName: "spot",
+	})
+	state.SetId("spot")
+
+	diags = resource.ReadContext(ctx, state, meta)
+	r.Len(diags, 1)
+	r.Equal(diag.Warning, diags[0].Severity)
+	r.Equal("spot", state.Id())
+
+	diags = resource.CreateContext(ctx, state, meta)
+	r.True(diags.HasError())
+	r.Contains(diags[0].Summary, "offline mode")
+
+	_, err := resource.Importer.StateContext(ctx, state, meta)
+	r.ErrorContains(err, "offline mode")
+
+	dataSource := p.DataSourcesMap["castai_node_template_match"]
+	diags = dataSource.ReadContext(ctx, schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{}), meta)
+	r.True(diags.HasError())
+
+	dataSource = p.DataSourcesMap["castai_eks_settings"]
+	diags = dataSource.ReadContext(ctx, schema.TestResourceDataRaw(t, dataSource.Schema, map[string]interface{}{
+		EKSSettingsFieldAccountId: "123456789012",
+		EKSSettingsFieldRegion:    "eu-central-1",
+		EKSSettingsFieldVpc:       "vpc-1",
+		EKSSettingsFieldCluster:   "test",
+	}), meta)
+	r.Nil(diags)
+}
@@ -22,6 +22,7 @@ const (
 	FieldAKSClusterClientID          = "client_id"
 	FieldAKSClusterClientSecret      = "client_secret"
 	FieldAKSClusterTenantID          = "tenant_id"
+	FieldAKSClusterNetworkPlugin     = "network_plugin"
 )
 
 func resourceAKSCluster() *schema.Resource {
@@ -66,6 +67,15 @@ func resourceAKSCluster() *schema.Resource {
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
 				Description:      "Azure resource group in which nodes are and will be created.",
 			},
+			FieldAKSClusterNetworkPlugin: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"azure", "kubenet"}, false)),
+				Description: "Network plugin in use by the cluster, `azure` or `kubenet`. It's detected by CAST AI agent " +
+					"when not set.",
+			},
 			FieldAKSClusterTenantID: {
 				Type:             schema.TypeString,
 				Required:         true,
@@ -101,6 +111,7 @@ func resourceAKSCluster() *schema.Resource {
 				Computed:    true,
 				Description: "CAST AI internal credentials ID",
 			},
+			FieldClusterResumeOnboarding: clusterResumeOnboardingSchema(FieldAKSClusterClientSecret),
 		},
 	}
 }
@@ -133,6 +144,9 @@ func resourceCastaiAKSClusterRead(ctx context.Context, data *schema.ResourceData
 		if err := data.Set(FieldAKSClusterRegion, toString(aks.Region)); err != nil {
 			return diag.FromErr(fmt.Errorf("setting region: %w", err))
 		}
+		if err := data.Set(FieldAKSClusterNetworkPlugin, toString(aks.NetworkPlugin)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting network plugin: %w", err))
+		}
 	}
 
 	return nil
@@ -150,6 +164,9 @@ func resourceCastaiAKSClusterCreate(ctx context.Context, data *schema.ResourceDa
 		SubscriptionId:    toPtr(data.Get(FieldAKSClusterSubscriptionID).(string)),
 		NodeResourceGroup: toPtr(data.Get(FieldAKSClusterNodeResourceGroup).(string)),
 	}
+	if v, ok := data.GetOk(FieldAKSClusterNetworkPlugin); ok {
+		req.Aks.NetworkPlugin = toPtr(v.(string))
+	}
 
 	log.Printf("[INFO] Registering new external AKS cluster: %#v", req)
 
@@ -158,18 +175,22 @@ func resourceCastaiAKSClusterCreate(ctx context.Context, data *schema.ResourceDa
 		return diag.FromErr(checkErr)
 	}
 
-	clusterID := *resp.JSON200.Id
-	tkn, err := createClusterToken(ctx, client, clusterID)
+	// Cluster id is kept in state from now on, so a failure of later phases doesn't leave the cluster registered twice.
+	data.SetId(*resp.JSON200.Id)
+	tkn, err := createClusterToken(ctx, client, data.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		return clusterTokenFailed(data.Id(), err)
 	}
 	if err := data.Set(FieldClusterToken, tkn); err != nil {
 		return diag.FromErr(fmt.Errorf("setting cluster token: %w", err))
 	}
-	data.SetId(clusterID)
 
 	if err := updateAKSClusterSettings(ctx, data, client); err != nil {
-		return diag.FromErr(err)
+		diags := clusterCredentialsFailed(data, FieldAKSClusterClientSecret, err)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceCastaiAKSClusterRead(ctx, data, meta)...)
 	}
 	log.Printf("[INFO] Cluster with id %q has been registered, don't forget to install castai-agent helm chart", data.Id())
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -69,11 +70,21 @@ func TestAKSClusterResourceReadContext(t *testing.T) {
 	r.False(result.HasError())
 	r.Equal(`ID = b6bfc074-a267-400f-b8f1-db0850c369b1
 credentials_id = 9b8d0456-177b-4a3d-b162-e68030d656aa
+network_plugin = calico
 region = westeurope
 Tainted = false
 `, data.State().String())
 }
 
+// testAccAKSPreCheck skips the test when Azure credentials aren't configured, so AKS acceptance tests only run where
+// an AKS cluster is available.
+func testAccAKSPreCheck(t *testing.T) {
+	if os.Getenv("ARM_SUBSCRIPTION_ID") == "" {
+		t.Skip("ARM_SUBSCRIPTION_ID must be set for AKS acceptance tests")
+	}
+	testAccPreCheck(t)
+}
+
 func TestAccResourceAKSCluster(t *testing.T) {
 	rName := fmt.Sprintf("%v-aks-%v", ResourcePrefix, acctest.RandString(8))
 	resourceName := "castai_aks_cluster.test"
@@ -98,19 +109,21 @@ func TestAccResourceAKSCluster(t *testing.T) {
 				),
 			},
 		},
-		ExternalProviders: map[string]resource.ExternalProvider{
-			"azurerm": {
-				Source:            "hashicorp/azurerm",
-				VersionConstraint: "~> 3.7.0",
-			},
-			"azuread": {
-				Source:            "hashicorp/azuread",
-				VersionConstraint: "~> 2.22.0",
-			},
-		},
+		ExternalProviders: testAccAzureProviders,
 	})
 }
 
+var testAccAzureProviders = map[string]resource.ExternalProvider{
+	"azurerm": {
+		Source:            "hashicorp/azurerm",
+		VersionConstraint: "~> 3.7.0",
+	},
+	"azuread": {
+		Source:            "hashicorp/azuread",
+		VersionConstraint: "~> 2.22.0",
+	},
+}
+
 func testAccAKSClusterConfig(rName string, clusterName string, resourceGroupName, nodeResourceGroup string) string {
 	return ConfigCompose(testAccAzureConfig(rName, resourceGroupName, nodeResourceGroup), fmt.Sprintf(`
 resource "castai_aks_cluster" "test" {
@@ -0,0 +1,205 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	FieldAPITokenName           = "name"
+	FieldAPITokenReadOnly       = "read_only"
+	FieldAPITokenActive         = "active"
+	FieldAPITokenOrganizationID = "organization_id"
+	FieldAPITokenToken          = "token"
+	FieldAPITokenCreatedAt      = "created_at"
+)
+
+func resourceAPIToken() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAPITokenCreate,
+		ReadContext:   resourceAPITokenRead,
+		UpdateContext: resourceAPITokenUpdate,
+		DeleteContext: resourceAPITokenDelete,
+		Description:   "CAST AI API token resource to manage access tokens of the organization",
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+			Delete: schema.DefaultTimeout(1 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldAPITokenName: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "Name of the token, must be unique among active tokens of the organization",
+			},
+			FieldAPITokenReadOnly: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     false,
+				Description: "Whether the token has read only access. Tokens have full access by default",
+			},
+			FieldAPITokenActive: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the token can be used. Inactive tokens are rejected by CAST AI API",
+			},
+			FieldAPITokenOrganizationID: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI organization id to create the token in. Defaults to organization of the provider's token",
+			},
+			FieldAPITokenToken: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Secret of the token. It's only returned when the token is created",
+			},
+			FieldAPITokenCreatedAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Token creation time in RFC3339 format",
+			},
+		},
+	}
+}
+
+func resourceAPITokenCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	orgID, err := apiTokenOrganizationID(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resp, err := client.CreateAuthTokenWithResponse(ctx, &sdk.CreateAuthTokenParams{XCastAiOrganizationId: orgID}, sdk.CreateAuthTokenJSONRequestBody{
+		Name:     d.Get(FieldAPITokenName).(string),
+		Readonly: d.Get(FieldAPITokenReadOnly).(bool),
+		Active:   lo.ToPtr(d.Get(FieldAPITokenActive).(bool)),
+	})
+	if checkErr := sdk.CheckCreatedResponse(resp, err); checkErr != nil {
+		return diag.FromErr(checkErr)
+	}
+
+	d.SetId(lo.FromPtr(resp.JSON201.Id))
+	if err := d.Set(FieldAPITokenToken, lo.FromPtr(resp.JSON201.Token)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting token: %w", err))
+	}
+
+	return resourceAPITokenRead(ctx, d, meta)
+}
+
+func resourceAPITokenRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	orgID, err := apiTokenOrganizationID(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resp, err := client.GetAuthTokenWithResponse(ctx, d.Id(), &sdk.GetAuthTokenParams{XCastAiOrganizationId: orgID})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// Deleted tokens might still be returned by the API.
+	if removeFromStateIfNotFound(d, "API token", isNotFound(resp) || (resp.JSON200 != nil && resp.JSON200.DeletedAt != nil)) {
+		return nil
+	}
+	if err := sdk.CheckOKResponse(resp, err); err != nil {
+		return diag.FromErr(err)
+	}
+
+	token := resp.JSON200
+	if err := d.Set(FieldAPITokenName, token.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %w", err))
+	}
+	if err := d.Set(FieldAPITokenReadOnly, token.Readonly); err != nil {
+		return diag.FromErr(fmt.Errorf("setting read only: %w", err))
+	}
+	if err := d.Set(FieldAPITokenActive, lo.FromPtr(token.Active)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting active: %w", err))
+	}
+	if token.CreatedAt != nil {
+		if err := d.Set(FieldAPITokenCreatedAt, token.CreatedAt.Format(time.RFC3339)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting created at: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func resourceAPITokenUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.HasChange(FieldAPITokenActive) {
+		return nil
+	}
+
+	client := meta.(*ProviderConfig).api
+
+	orgID, err := apiTokenOrganizationID(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resp, err := client.UpdateAuthTokenWithResponse(ctx, d.Id(), &sdk.UpdateAuthTokenParams{XCastAiOrganizationId: orgID}, sdk.UpdateAuthTokenJSONRequestBody{
+		Active: d.Get(FieldAPITokenActive).(bool),
+	})
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(checkErr)
+	}
+
+	return resourceAPITokenRead(ctx, d, meta)
+}
+
+func resourceAPITokenDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	orgID, err := apiTokenOrganizationID(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resp, err := client.DeleteAuthTokenWithResponse(ctx, d.Id(), &sdk.DeleteAuthTokenParams{XCastAiOrganizationId: orgID})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if isNotFound(resp) {
+		return nil
+	}
+	if err := sdk.CheckResponseNoContent(resp, err); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// apiTokenOrganizationID returns organization header value, nil means organization of the provider's token.
+func apiTokenOrganizationID(d *schema.ResourceData) (*sdk.HeaderOrganizationId, error) {
+	v, ok := d.GetOk(FieldAPITokenOrganizationID)
+	if !ok {
+		return nil, nil
+	}
+
+	orgID, err := uuid.Parse(v.(string))
+	if err != nil {
+		return nil, fmt.Errorf("parsing organization id: %w", err)
+	}
+
+	return &orgID, nil
+}
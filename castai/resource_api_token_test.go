@@ -0,0 +1,160 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestAPITokenResourceCreateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	id := "4e4cd9eb-a9ab-4a9c-8f2c-5d7e3b1c2a10"
+	orgID := "0d0111f9-e5a4-4acc-85b2-4c3a8318dfc2"
+
+	mockClient.EXPECT().
+		CreateAuthToken(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params *sdk.CreateAuthTokenParams, req sdk.CreateAuthTokenJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal(orgID, params.XCastAiOrganizationId.String())
+			r.Equal("ci", req.Name)
+			r.True(req.Readonly)
+			r.True(lo.FromPtr(req.Active))
+
+			return testJSONResponse(http.StatusCreated, `{"id": "`+id+`", "name": "ci", "readonly": true, "active": true, "token": "secret"}`), nil
+		})
+	mockClient.EXPECT().
+		GetAuthToken(gomock.Any(), id, gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, `{"id": "`+id+`", "name": "ci", "readonly": true, "active": true, "createdAt": "2023-04-18T10:00:00Z"}`), nil)
+
+	resource := resourceAPIToken()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldAPITokenName:           "ci",
+		FieldAPITokenReadOnly:       true,
+		FieldAPITokenOrganizationID: orgID,
+	})
+
+	result := resource.CreateContext(context.Background(), data, provider)
+	r.Nil(result)
+	r.Equal(id, data.Id())
+	r.Equal("secret", data.Get(FieldAPITokenToken))
+	r.Equal("2023-04-18T10:00:00Z", data.Get(FieldAPITokenCreatedAt))
+}
+
+func TestAPITokenResourceReadContext(t *testing.T) {
+	id := "4e4cd9eb-a9ab-4a9c-8f2c-5d7e3b1c2a10"
+
+	tests := map[string]struct {
+		response   *http.Response
+		expectedID string
+	}{
+		"token exists": {
+			response:   testJSONResponse(http.StatusOK, `{"id": "`+id+`", "name": "ci", "readonly": false, "active": false}`),
+			expectedID: id,
+		},
+		"token not found": {
+			response: testJSONResponse(http.StatusNotFound, `{"message": "not found"}`),
+		},
+		"token deleted": {
+			response: testJSONResponse(http.StatusOK, `{"id": "`+id+`", "name": "ci", "deletedAt": "2023-04-18T10:00:00Z"}`),
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			mockClient.EXPECT().
+				GetAuthToken(gomock.Any(), id, gomock.Any()).
+				Return(tt.response, nil)
+
+			resource := resourceAPIToken()
+			state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+				FieldAPITokenName:   cty.StringVal("ci"),
+				FieldAPITokenActive: cty.True,
+				FieldAPITokenToken:  cty.StringVal("secret"),
+			}), 0)
+			state.ID = id
+			data := resource.Data(state)
+
+			result := resource.ReadContext(context.Background(), data, provider)
+			r.Nil(result)
+			r.Equal(tt.expectedID, data.Id())
+			if tt.expectedID != "" {
+				r.False(data.Get(FieldAPITokenActive).(bool))
+				r.Equal("secret", data.Get(FieldAPITokenToken))
+			}
+		})
+	}
+}
+
+func TestAPITokenResourceUpdateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	id := "4e4cd9eb-a9ab-4a9c-8f2c-5d7e3b1c2a10"
+	mockClient.EXPECT().
+		UpdateAuthToken(gomock.Any(), id, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, params *sdk.UpdateAuthTokenParams, req sdk.UpdateAuthTokenJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Nil(params.XCastAiOrganizationId)
+			r.False(req.Active)
+
+			return testJSONResponse(http.StatusOK, `{"id": "`+id+`", "name": "ci", "active": false}`), nil
+		})
+	mockClient.EXPECT().
+		GetAuthToken(gomock.Any(), id, gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, `{"id": "`+id+`", "name": "ci", "active": false}`), nil)
+
+	resource := resourceAPIToken()
+	state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+		FieldAPITokenName:   cty.StringVal("ci"),
+		FieldAPITokenActive: cty.True,
+	}), 0)
+	state.ID = id
+	diff, err := resource.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		FieldAPITokenName:   "ci",
+		FieldAPITokenActive: false,
+	}), provider)
+	r.NoError(err)
+	data, err := schema.InternalMap(resource.Schema).Data(state, diff)
+	r.NoError(err)
+
+	result := resource.UpdateContext(context.Background(), data, provider)
+	r.Nil(result)
+	r.False(data.Get(FieldAPITokenActive).(bool))
+}
+
+func TestAPITokenResourceDeleteContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	id := "4e4cd9eb-a9ab-4a9c-8f2c-5d7e3b1c2a10"
+	mockClient.EXPECT().
+		DeleteAuthToken(gomock.Any(), id, gomock.Any()).
+		Return(&http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil)
+
+	resource := resourceAPIToken()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldAPITokenName: "ci",
+	})
+	data.SetId(id)
+
+	result := resource.DeleteContext(context.Background(), data, provider)
+	r.Nil(result)
+}
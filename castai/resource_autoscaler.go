@@ -19,13 +19,15 @@ import (
 )
 
 const (
-	FieldAutoscalerPoliciesJSON = "autoscaler_policies_json"
-	FieldClusterId              = "cluster_id"
-	FieldAutoscalerPolicies     = "autoscaler_policies"
+	FieldAutoscalerPoliciesJSON    = "autoscaler_policies_json"
+	FieldAutoscalerPolicies        = "autoscaler_policies"
+	FieldAutoscalerNodeConstraints = "node_constraints"
+	FieldAutoscalerHeadroom        = "headroom"
+	FieldAutoscalerHeadroomSpot    = "headroom_spot"
 )
 
 func resourceAutoscaler() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		ReadContext:   resourceCastaiAutoscalerRead,
 		CreateContext: resourceCastaiAutoscalerCreate,
 		UpdateContext: resourceCastaiAutoscalerUpdate,
@@ -38,32 +40,133 @@ func resourceAutoscaler() *schema.Resource {
 		},
 
 		Schema: map[string]*schema.Schema{
-			FieldClusterId: {
+			FieldClusterID: {
 				Type:             schema.TypeString,
-				Optional:         true,
+				Required:         true,
+				ForceNew:         true,
 				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
 				Description:      "CAST AI cluster id",
 			},
 			FieldAutoscalerPoliciesJSON: {
-				Type:        schema.TypeString,
-				Description: "autoscaler policies JSON string to override current autoscaler settings",
-				Optional:    true,
+				Type:             schema.TypeString,
+				Description:      "autoscaler policies JSON string to override current autoscaler settings",
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentJSONDiffs,
 			},
+			FieldAutoscalerNodeConstraints: {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enabled": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether node constraints are applied.",
+						},
+						"min_cpu_cores": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							Description:      "Min CPU cores of added nodes, 0 means not limited.",
+						},
+						"max_cpu_cores": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							Description:      "Max CPU cores of added nodes, 0 means not limited.",
+						},
+						"min_ram_mib": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							Description:      "Min memory (MiB) of added nodes, 0 means not limited.",
+						},
+						"max_ram_mib": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							Description:      "Max memory (MiB) of added nodes, 0 means not limited.",
+						},
+					},
+				},
+				Description: "Constraints of nodes added by the unschedulable pods policy, including headroom nodes. " +
+					"Takes precedence over `unschedulablePods.nodeConstraints` of `autoscaler_policies_json`. " +
+					"Constraints are left as they are when the block is removed.",
+			},
+			FieldAutoscalerHeadroom: autoscalerHeadroomSchema("Headroom of on-demand nodes: spare capacity kept in " +
+				"the cluster as a percentage of allocatable CPU and memory of the nodes. Takes precedence over " +
+				"`unschedulablePods.headroom` of `autoscaler_policies_json`. Headroom is left as it is when the block is removed."),
+			FieldAutoscalerHeadroomSpot: autoscalerHeadroomSchema("Headroom of spot nodes: spare capacity kept in the " +
+				"cluster as a percentage of allocatable CPU and memory of the nodes. Takes precedence over " +
+				"`unschedulablePods.headroomSpot` of `autoscaler_policies_json`. Headroom is left as it is when the block is removed."),
 			FieldAutoscalerPolicies: {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "computed value to store full policies configuration",
 			},
 		},
+		CustomizeDiff: autoscalerNodeConstraintsDiff,
+		SchemaVersion: 1,
+	}
+	// Cluster id only became required in version 1, the type of the state is the same.
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: autoscalerStateUpgradeV0,
+		},
 	}
+
+	return r
+}
+
+func autoscalerHeadroomSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"enabled": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Whether headroom is kept.",
+				},
+				"cpu_percentage": {
+					Type:             schema.TypeInt,
+					Required:         true,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+					Description:      "Additional CPU capacity in percent.",
+				},
+				"memory_percentage": {
+					Type:             schema.TypeInt,
+					Required:         true,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+					Description:      "Additional memory capacity in percent.",
+				},
+			},
+		},
+		Description: description,
+	}
+}
+
+// autoscalerStateUpgradeV0 sets cluster id of states written while it was optional. The resource id is always the
+// cluster id.
+func autoscalerStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if clusterID, _ := rawState[FieldClusterID].(string); clusterID == "" {
+		rawState[FieldClusterID] = rawState["id"]
+	}
+
+	return rawState, nil
 }
 
 func resourceCastaiAutoscalerDelete(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clusterId := getClusterId(data)
-	if clusterId == "" {
-		log.Print("[INFO] ClusterId is missing. Will skip operation.")
-		return nil
-	}
+	clusterLocks.Lock(clusterId)
+	defer clusterLocks.Unlock(clusterId)
 
 	err := upsertPolicies(ctx, meta, clusterId, `{"enabled":false}`)
 	if err != nil {
@@ -76,6 +179,9 @@ func resourceCastaiAutoscalerDelete(ctx context.Context, data *schema.ResourceDa
 
 func resourceCastaiAutoscalerRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	err := readAutoscalerPolicies(ctx, data, meta)
+	if removeFromStateIfNotFound(data, "Autoscaler policies", isNotFoundErr(err)) {
+		return nil
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -84,12 +190,6 @@ func resourceCastaiAutoscalerRead(ctx context.Context, data *schema.ResourceData
 }
 
 func resourceCastaiAutoscalerCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	clusterId := getClusterId(data)
-	if clusterId == "" {
-		log.Print("[INFO] ClusterId is missing. Will skip operation.")
-		return nil
-	}
-
 	err := updateAutoscalerPolicies(ctx, data, meta)
 	if err != nil {
 		return diag.FromErr(err)
@@ -116,7 +216,7 @@ func getCurrentPolicies(ctx context.Context, client *sdk.ClientWithResponses, cl
 	if err != nil {
 		return nil, err
 	} else if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("cluster %s policies do not exist at CAST AI", clusterId)
+		return nil, newNotFoundError("cluster %s policies do not exist at CAST AI", clusterId)
 	}
 
 	bytes, err := io.ReadAll(resp.Body)
@@ -132,10 +232,9 @@ func getCurrentPolicies(ctx context.Context, client *sdk.ClientWithResponses, cl
 
 func updateAutoscalerPolicies(ctx context.Context, data *schema.ResourceData, meta interface{}) error {
 	clusterId := getClusterId(data)
-	if clusterId == "" {
-		log.Print("[INFO] ClusterId is missing. Will skip operation.")
-		return nil
-	}
+	// Policies are read and written back as a whole, so other resources changing them must wait.
+	clusterLocks.Lock(clusterId)
+	defer clusterLocks.Unlock(clusterId)
 
 	err := readAutoscalerPolicies(ctx, data, meta)
 	if err != nil {
@@ -173,11 +272,6 @@ func readAutoscalerPolicies(ctx context.Context, data *schema.ResourceData, meta
 	defer log.Printf("[INFO] AUTOSCALER policies get call end")
 
 	clusterId := getClusterId(data)
-	if clusterId == "" {
-		log.Print("[INFO] ClusterId is missing. Will skip operation.")
-		return nil
-	}
-
 	policies, err := getChangedPolicies(ctx, data, meta, clusterId)
 	if err != nil {
 		return err
@@ -194,15 +288,19 @@ func readAutoscalerPolicies(ctx context.Context, data *schema.ResourceData, meta
 
 func getChangedPolicies(ctx context.Context, data *schema.ResourceData, meta interface{}, clusterId sdk.ClusterId) ([]byte, error) {
 	policyChangesJSON, found := data.GetOk(FieldAutoscalerPoliciesJSON)
-	if !found {
+	unschedulablePods := toUnschedulablePodsPatch(data)
+	if !found && unschedulablePods == nil {
 		log.Printf("[DEBUG] policies json not provided. Skipping autoscaler policies changes")
 		return nil, nil
 	}
 
-	policyChanges := []byte(policyChangesJSON.(string))
-	if !json.Valid(policyChanges) {
-		log.Printf("[WARN] policies JSON invalid: %v", string(policyChanges))
-		return nil, fmt.Errorf("policies JSON invalid")
+	var policyChanges []byte
+	if found {
+		policyChanges = []byte(policyChangesJSON.(string))
+		if !json.Valid(policyChanges) {
+			log.Printf("[WARN] policies JSON invalid: %v", string(policyChanges))
+			return nil, fmt.Errorf("policies JSON invalid")
+		}
 	}
 
 	client := meta.(*ProviderConfig).api
@@ -210,20 +308,90 @@ func getChangedPolicies(ctx context.Context, data *schema.ResourceData, meta int
 	currentPolicies, err := getCurrentPolicies(ctx, client, clusterId)
 	if err != nil {
 		log.Printf("[WARN] Getting current policies: %v", err)
-		return nil, fmt.Errorf("failed to get policies from API: %v", err)
+		return nil, fmt.Errorf("failed to get policies from API: %w", err)
 	}
 
-	policies, err := jsonpatch.MergePatch(currentPolicies, policyChanges)
-	if err != nil {
-		log.Printf("[WARN] Failed to merge policy changes: %v", err)
-		return nil, fmt.Errorf("failed to merge policies: %v", err)
+	policies := currentPolicies
+	if policyChanges != nil {
+		policies, err = jsonpatch.MergePatch(policies, policyChanges)
+		if err != nil {
+			log.Printf("[WARN] Failed to merge policy changes: %v", err)
+			return nil, fmt.Errorf("failed to merge policies: %v", err)
+		}
+	}
+	if unschedulablePods != nil {
+		policies, err = jsonpatch.MergePatch(policies, unschedulablePods)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge unschedulable pods policy: %w", err)
+		}
 	}
 
 	return policies, nil
 }
 
+// toUnschedulablePodsPatch returns JSON merge patch of policies which sets the configured node constraints and headroom,
+// nil is returned when none of them are configured.
+func toUnschedulablePodsPatch(data *schema.ResourceData) []byte {
+	unschedulablePods := map[string]interface{}{}
+	if c := configuredBlock(data, FieldAutoscalerNodeConstraints); c != nil {
+		unschedulablePods["nodeConstraints"] = sdk.PoliciesV1NodeConstraints{
+			Enabled:     toPtr(c["enabled"].(bool)),
+			MinCpuCores: toPtr(int32(c["min_cpu_cores"].(int))),
+			MaxCpuCores: toPtr(int32(c["max_cpu_cores"].(int))),
+			MinRamMib:   toPtr(int32(c["min_ram_mib"].(int))),
+			MaxRamMib:   toPtr(int32(c["max_ram_mib"].(int))),
+		}
+	}
+	if h := configuredBlock(data, FieldAutoscalerHeadroom); h != nil {
+		unschedulablePods["headroom"] = toHeadroom(h)
+	}
+	if h := configuredBlock(data, FieldAutoscalerHeadroomSpot); h != nil {
+		unschedulablePods["headroomSpot"] = toHeadroom(h)
+	}
+	if len(unschedulablePods) == 0 {
+		return nil
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{"unschedulablePods": unschedulablePods})
+
+	return patch
+}
+
+func toHeadroom(h map[string]interface{}) *sdk.PoliciesV1Headroom {
+	return &sdk.PoliciesV1Headroom{
+		Enabled:          toPtr(h["enabled"].(bool)),
+		CpuPercentage:    toPtr(int32(h["cpu_percentage"].(int))),
+		MemoryPercentage: toPtr(int32(h["memory_percentage"].(int))),
+	}
+}
+
+// configuredBlock returns attributes of the single item block, nil is returned when the block isn't set.
+func configuredBlock(data *schema.ResourceData, field string) map[string]interface{} {
+	v, ok := data.Get(field).([]interface{})
+	if !ok || len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	return v[0].(map[string]interface{})
+}
+
+// autoscalerNodeConstraintsDiff rejects node constraints with max values lower than min values.
+func autoscalerNodeConstraintsDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	v, ok := d.Get(FieldAutoscalerNodeConstraints).([]interface{})
+	if !ok || len(v) == 0 || v[0] == nil {
+		return nil
+	}
+	c := v[0].(map[string]interface{})
+
+	prefix := FieldAutoscalerNodeConstraints + ".0"
+	if err := validateConstraintRange(c, prefix, "min_cpu_cores", "max_cpu_cores"); err != nil {
+		return err
+	}
+	return validateConstraintRange(c, prefix, "min_ram_mib", "max_ram_mib")
+}
+
 func getClusterId(data *schema.ResourceData) sdk.ClusterId {
-	value, found := data.GetOk(FieldClusterId)
+	value, found := data.GetOk(FieldClusterID)
 	if !found {
 		return ""
 	}
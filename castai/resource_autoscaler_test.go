@@ -12,6 +12,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
 
@@ -158,7 +159,7 @@ func TestAutoscalerResource_PoliciesUpdateAction(t *testing.T) {
 	clusterId := "cluster_id"
 	val := cty.ObjectVal(map[string]cty.Value{
 		FieldAutoscalerPoliciesJSON: cty.StringVal(policyChanges),
-		FieldClusterId:              cty.StringVal(clusterId),
+		FieldClusterID:              cty.StringVal(clusterId),
 	})
 	state := terraform.NewInstanceStateShimmedFromValue(val, 0)
 	data := resource.Data(state)
@@ -276,7 +277,7 @@ func TestAutoscalerResource_PoliciesUpdateAction_Fail(t *testing.T) {
 	clusterId := "cluster_id"
 	val := cty.ObjectVal(map[string]cty.Value{
 		FieldAutoscalerPoliciesJSON: cty.StringVal(policyChanges),
-		FieldClusterId:              cty.StringVal(clusterId),
+		FieldClusterID:              cty.StringVal(clusterId),
 	})
 	state := terraform.NewInstanceStateShimmedFromValue(val, 0)
 	data := resource.Data(state)
@@ -299,6 +300,150 @@ func TestAutoscalerResource_PoliciesUpdateAction_Fail(t *testing.T) {
 	r.Equal(`expected status code 200, received: status=400 body={"message":"policies config: Evictor policy management is not allowed: Evictor installed externally. Uninstall Evictor first and try again.","fieldViolations":[]`, result[0].Summary)
 }
 
+func TestAutoscalerResource_ReadRemovedFromStateWhenNotFound(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	resource := resourceAutoscaler()
+
+	clusterId := "cluster_id"
+	val := cty.ObjectVal(map[string]cty.Value{
+		FieldAutoscalerPoliciesJSON: cty.StringVal(`{"enabled":true}`),
+		FieldClusterID:              cty.StringVal(clusterId),
+	})
+	state := terraform.NewInstanceStateShimmedFromValue(val, 0)
+	state.ID = clusterId
+	data := resource.Data(state)
+
+	mockClient.EXPECT().PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId, gomock.Any()).
+		Return(&http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewReader([]byte(`{}`)))}, nil)
+
+	result := resource.ReadContext(context.Background(), data, provider)
+	r.Nil(result)
+	r.Empty(data.Id())
+}
+
+func TestAutoscalerResource_PoliciesJSONDiffSuppressed(t *testing.T) {
+	tests := map[string]struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		"same string": {
+			old:      `{"enabled":true}`,
+			new:      `{"enabled":true}`,
+			suppress: true,
+		},
+		"different formatting and key order": {
+			old:      `{"enabled":true,"unschedulablePods":{"enabled":true,"headroom":{"cpuPercentage":10}}}`,
+			new:      "{\n  \"unschedulablePods\": {\n    \"headroom\": {\"cpuPercentage\": 10},\n    \"enabled\": true\n  },\n  \"enabled\": true\n}",
+			suppress: true,
+		},
+		"different values": {
+			old:      `{"enabled":true}`,
+			new:      `{"enabled":false}`,
+			suppress: false,
+		},
+		"invalid json": {
+			old:      `{"enabled":true}`,
+			new:      `{"enabled":true`,
+			suppress: false,
+		},
+		"new value": {
+			old:      ``,
+			new:      `{"enabled":true}`,
+			suppress: false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			suppress := resourceAutoscaler().Schema[FieldAutoscalerPoliciesJSON].DiffSuppressFunc
+			r.Equal(tt.suppress, suppress(FieldAutoscalerPoliciesJSON, tt.old, tt.new, nil))
+		})
+	}
+}
+
+func TestAutoscalerResource_UnschedulablePodsUpdateAction(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId, gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, `{
+			"enabled": true,
+			"unschedulablePods": {
+				"enabled": true,
+				"headroom": {"enabled": true, "cpuPercentage": 10, "memoryPercentage": 10},
+				"headroomSpot": {"enabled": true, "cpuPercentage": 10, "memoryPercentage": 10},
+				"nodeConstraints": {"enabled": false, "minCpuCores": 2, "maxCpuCores": 32, "minRamMib": 4096, "maxRamMib": 262144}
+			}
+		}`), nil)
+	mockClient.EXPECT().PoliciesAPIUpsertClusterPoliciesWithBody(gomock.Any(), clusterId, "application/json", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ string, body io.Reader, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			got, _ := io.ReadAll(body)
+			// Typed settings take precedence over policies JSON, headroom which isn't configured is kept.
+			eq, err := JSONBytesEqual(got, []byte(`{
+				"enabled": true,
+				"unschedulablePods": {
+					"enabled": false,
+					"headroom": {"enabled": true, "cpuPercentage": 10, "memoryPercentage": 10},
+					"headroomSpot": {"enabled": false, "cpuPercentage": 20, "memoryPercentage": 15},
+					"nodeConstraints": {"enabled": true, "minCpuCores": 4, "maxCpuCores": 16, "minRamMib": 0, "maxRamMib": 0}
+				}
+			}`))
+			r.NoError(err)
+			r.True(eq, string(got))
+
+			return testJSONResponse(http.StatusOK, `{}`), nil
+		})
+
+	resource := resourceAutoscaler()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:              clusterId,
+		FieldAutoscalerPoliciesJSON: `{"unschedulablePods": {"enabled": false, "nodeConstraints": {"maxCpuCores": 96}, "headroomSpot": {"cpuPercentage": 50}}}`,
+		FieldAutoscalerNodeConstraints: []any{map[string]any{
+			"min_cpu_cores": 4,
+			"max_cpu_cores": 16,
+		}},
+		FieldAutoscalerHeadroomSpot: []any{map[string]any{
+			"enabled":           false,
+			"cpu_percentage":    20,
+			"memory_percentage": 15,
+		}},
+	})
+
+	r.Nil(resource.CreateContext(context.Background(), data, provider))
+	r.Equal(clusterId, data.Id())
+}
+
+func TestAutoscalerResource_NodeConstraintsDiff(t *testing.T) {
+	r := require.New(t)
+
+	resource := resourceAutoscaler()
+	_, err := resource.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]any{
+		FieldClusterID: "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		FieldAutoscalerNodeConstraints: []any{map[string]any{
+			"min_ram_mib": 8192,
+			"max_ram_mib": 4096,
+		}},
+	}), nil)
+	r.EqualError(err, "node_constraints.0.max_ram_mib (4096) must be greater than or equal to node_constraints.0.min_ram_mib (8192)")
+}
+
+func TestAutoscalerResourceStateUpgradeV0(t *testing.T) {
+	r := require.New(t)
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	upgraded, err := autoscalerStateUpgradeV0(context.Background(), map[string]interface{}{"id": clusterId}, nil)
+	r.NoError(err)
+	r.Equal(map[string]interface{}{"id": clusterId, FieldClusterID: clusterId}, upgraded)
+}
+
 func JSONBytesEqual(a, b []byte) (bool, error) {
 	var j, j2 interface{}
 	if err := json.Unmarshal(a, &j); err != nil {
@@ -0,0 +1,257 @@
+package castai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	FieldClusterSettingsSpotBackupsEnabled             = "spot_backups_enabled"
+	FieldClusterSettingsSpotBackupRestoreRateSeconds   = "spot_backup_restore_rate_seconds"
+	FieldClusterSettingsSpotDiversityEnabled           = "spot_diversity_enabled"
+	FieldClusterSettingsEmptyNodesDeletionEnabled      = "empty_nodes_deletion_enabled"
+	FieldClusterSettingsEmptyNodesDeletionDelaySeconds = "empty_nodes_deletion_delay_seconds"
+)
+
+const (
+	clusterSettingsMaxConflictRetries    = 5
+	clusterSettingsConflictRetryInterval = 500 * time.Millisecond
+)
+
+// clusterSettingsPaths maps settings to their location in cluster policies.
+var clusterSettingsPaths = map[string][]string{
+	FieldClusterSettingsSpotBackupsEnabled:             {"spotInstances", "spotBackups", "enabled"},
+	FieldClusterSettingsSpotBackupRestoreRateSeconds:   {"spotInstances", "spotBackups", "spotBackupRestoreRateSeconds"},
+	FieldClusterSettingsSpotDiversityEnabled:           {"spotInstances", "spotDiversityEnabled"},
+	FieldClusterSettingsEmptyNodesDeletionEnabled:      {"nodeDownscaler", "emptyNodes", "enabled"},
+	FieldClusterSettingsEmptyNodesDeletionDelaySeconds: {"nodeDownscaler", "emptyNodes", "delaySeconds"},
+}
+
+func resourceClusterSettings() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceClusterSettingsCreate,
+		ReadContext:   resourceClusterSettingsRead,
+		UpdateContext: resourceClusterSettingsUpdate,
+		DeleteContext: resourceClusterSettingsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: clusterSettingsStateImporter,
+		},
+		Description: "CAST AI cluster settings resource to manage cluster-wide flags independently from `castai_autoscaler`. " +
+			"Only configured settings are changed, other settings are kept as they are. Settings are left unchanged when " +
+			"the resource is destroyed.",
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+			Delete: schema.DefaultTimeout(1 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldClusterID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id",
+			},
+			FieldClusterSettingsSpotBackupsEnabled: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether on-demand backup nodes are created when spot instances are not available",
+			},
+			FieldClusterSettingsSpotBackupRestoreRateSeconds: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "How often in seconds backup nodes are replaced with spot instances once they are available again",
+			},
+			FieldClusterSettingsSpotDiversityEnabled: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether autoscaler balances between diverse and cost optimal spot instance types",
+			},
+			FieldClusterSettingsEmptyNodesDeletionEnabled: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether empty worker nodes are deleted",
+			},
+			FieldClusterSettingsEmptyNodesDeletionDelaySeconds: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      "How long in seconds a node has to be empty before it's deleted",
+			},
+		},
+	}
+}
+
+func resourceClusterSettingsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clusterID := d.Get(FieldClusterID).(string)
+
+	// Settings which aren't configured are computed, so they are read from the cluster instead.
+	fields := lo.Filter(lo.Keys(clusterSettingsPaths), func(field string, _ int) bool {
+		_, ok := d.GetOkExists(field)
+		return ok
+	})
+	if err := upsertClusterSettings(ctx, meta, clusterID, toClusterSettingsPatch(d, fields)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(clusterID)
+
+	return resourceClusterSettingsRead(ctx, d, meta)
+}
+
+func resourceClusterSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	current, err := getCurrentPolicies(ctx, client, d.Id())
+	if removeFromStateIfNotFound(d, "Cluster settings", isNotFoundErr(err)) {
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var policies sdk.PoliciesV1Policies
+	if err := json.Unmarshal(current, &policies); err != nil {
+		return diag.FromErr(fmt.Errorf("parsing policies: %w", err))
+	}
+
+	spot := lo.FromPtr(policies.SpotInstances)
+	spotBackups := lo.FromPtr(spot.SpotBackups)
+	emptyNodes := lo.FromPtr(lo.FromPtr(policies.NodeDownscaler).EmptyNodes)
+
+	if err := d.Set(FieldClusterID, d.Id()); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster id: %w", err))
+	}
+	if err := d.Set(FieldClusterSettingsSpotBackupsEnabled, lo.FromPtr(spotBackups.Enabled)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting spot backups enabled: %w", err))
+	}
+	if err := d.Set(FieldClusterSettingsSpotBackupRestoreRateSeconds, lo.FromPtr(spotBackups.SpotBackupRestoreRateSeconds)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting spot backup restore rate: %w", err))
+	}
+	if err := d.Set(FieldClusterSettingsSpotDiversityEnabled, lo.FromPtr(spot.SpotDiversityEnabled)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting spot diversity enabled: %w", err))
+	}
+	if err := d.Set(FieldClusterSettingsEmptyNodesDeletionEnabled, lo.FromPtr(emptyNodes.Enabled)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting empty nodes deletion enabled: %w", err))
+	}
+	if err := d.Set(FieldClusterSettingsEmptyNodesDeletionDelaySeconds, lo.FromPtr(emptyNodes.DelaySeconds)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting empty nodes deletion delay: %w", err))
+	}
+
+	return nil
+}
+
+func resourceClusterSettingsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	fields := lo.Filter(lo.Keys(clusterSettingsPaths), func(field string, _ int) bool {
+		return d.HasChange(field)
+	})
+	if len(fields) == 0 {
+		log.Printf("[INFO] Nothing to update in cluster settings")
+		return nil
+	}
+
+	if err := upsertClusterSettings(ctx, meta, d.Id(), toClusterSettingsPatch(d, fields)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceClusterSettingsRead(ctx, d, meta)
+}
+
+func resourceClusterSettingsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Settings have no "unset" state, so they are kept as they are.
+	return nil
+}
+
+// toClusterSettingsPatch returns JSON merge patch of cluster policies which sets the given fields.
+func toClusterSettingsPatch(d *schema.ResourceData, fields []string) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for _, field := range fields {
+		path := clusterSettingsPaths[field]
+
+		node := patch
+		for _, key := range path[:len(path)-1] {
+			child, ok := node[key].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[key] = child
+			}
+			node = child
+		}
+		node[path[len(path)-1]] = d.Get(field)
+	}
+
+	return patch
+}
+
+// upsertClusterSettings merges settings into current cluster policies, so other policies stay intact. Policies can be
+// changed outside of Terraform at the same time, so conflicting writes are retried with freshly read policies.
+func upsertClusterSettings(ctx context.Context, meta interface{}, clusterID string, patch map[string]interface{}) error {
+	if len(patch) == 0 {
+		return nil
+	}
+
+	client := meta.(*ProviderConfig).api
+
+	changes, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("marshaling cluster settings: %w", err)
+	}
+
+	clusterLocks.Lock(clusterID)
+	defer clusterLocks.Unlock(clusterID)
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = clusterSettingsConflictRetryInterval
+	return backoff.Retry(func() error {
+		current, err := getCurrentPolicies(ctx, client, clusterID)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		policies, err := jsonpatch.MergePatch(current, changes)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("failed to merge policies: %w", err))
+		}
+
+		resp, err := client.PoliciesAPIUpsertClusterPoliciesWithBodyWithResponse(ctx, clusterID, "application/json", bytes.NewReader(policies))
+		if err == nil && resp.StatusCode() == http.StatusConflict {
+			log.Printf("[WARN] Cluster %s policies were changed concurrently, retrying", clusterID)
+			return sdk.CheckOKResponse(resp, err)
+		}
+		if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+			return backoff.Permanent(checkErr)
+		}
+		return nil
+	}, backoff.WithContext(backoff.WithMaxRetries(b, clusterSettingsMaxConflictRetries), ctx))
+}
+
+func clusterSettingsStateImporter(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	if err := d.Set(FieldClusterID, d.Id()); err != nil {
+		return nil, fmt.Errorf("setting cluster id: %w", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,148 @@
+package castai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestClusterSettingsResourceCreateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	currentPolicies := `{
+		"enabled": true,
+		"spotInstances": {"enabled": true, "spotBackups": {"enabled": false, "spotBackupRestoreRateSeconds": 1800}},
+		"nodeDownscaler": {"enabled": true, "emptyNodes": {"enabled": true, "delaySeconds": 60}}
+	}`
+	// Only configured settings are changed.
+	updatedPolicies := `{
+		"enabled": true,
+		"spotInstances": {"enabled": true, "spotBackups": {"enabled": true, "spotBackupRestoreRateSeconds": 1800}},
+		"nodeDownscaler": {"enabled": true, "emptyNodes": {"enabled": false, "delaySeconds": 60}}
+	}`
+
+	gomock.InOrder(
+		mockClient.EXPECT().
+			PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, currentPolicies), nil),
+		mockClient.EXPECT().
+			PoliciesAPIUpsertClusterPoliciesWithBody(gomock.Any(), clusterId, "application/json", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, body io.Reader, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+				got, _ := io.ReadAll(body)
+				eq, err := JSONBytesEqual(got, []byte(updatedPolicies))
+				r.NoError(err)
+				r.True(eq, string(got))
+
+				return testJSONResponse(http.StatusOK, updatedPolicies), nil
+			}),
+		mockClient.EXPECT().
+			PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, updatedPolicies), nil),
+	)
+
+	resource := resourceClusterSettings()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID:                                clusterId,
+		FieldClusterSettingsSpotBackupsEnabled:        true,
+		FieldClusterSettingsEmptyNodesDeletionEnabled: false,
+	})
+
+	result := resource.CreateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal(clusterId, data.Id())
+	r.Equal(true, data.Get(FieldClusterSettingsSpotBackupsEnabled))
+	r.Equal(1800, data.Get(FieldClusterSettingsSpotBackupRestoreRateSeconds))
+	r.Equal(false, data.Get(FieldClusterSettingsSpotDiversityEnabled))
+	r.Equal(false, data.Get(FieldClusterSettingsEmptyNodesDeletionEnabled))
+	r.Equal(60, data.Get(FieldClusterSettingsEmptyNodesDeletionDelaySeconds))
+}
+
+func TestClusterSettingsResourceUpdateContextRetriesConflicts(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	currentPolicies := `{"enabled": true, "nodeDownscaler": {"emptyNodes": {"enabled": true, "delaySeconds": 60}}}`
+	// Policies were changed concurrently, the change must be kept.
+	concurrentPolicies := `{"enabled": false, "nodeDownscaler": {"emptyNodes": {"enabled": true, "delaySeconds": 60}}}`
+	updatedPolicies := `{"enabled": false, "nodeDownscaler": {"emptyNodes": {"enabled": true, "delaySeconds": 300}}}`
+
+	gomock.InOrder(
+		mockClient.EXPECT().
+			PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, currentPolicies), nil),
+		mockClient.EXPECT().
+			PoliciesAPIUpsertClusterPoliciesWithBody(gomock.Any(), clusterId, "application/json", gomock.Any()).
+			Return(testJSONResponse(http.StatusConflict, `{"message": "conflict"}`), nil),
+		mockClient.EXPECT().
+			PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, concurrentPolicies), nil),
+		mockClient.EXPECT().
+			PoliciesAPIUpsertClusterPoliciesWithBody(gomock.Any(), clusterId, "application/json", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, body io.Reader, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+				got, _ := io.ReadAll(body)
+				eq, err := JSONBytesEqual(got, []byte(updatedPolicies))
+				r.NoError(err)
+				r.True(eq, string(got))
+
+				return testJSONResponse(http.StatusOK, updatedPolicies), nil
+			}),
+		mockClient.EXPECT().
+			PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, updatedPolicies), nil),
+	)
+
+	resource := resourceClusterSettings()
+	state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+		FieldClusterID: cty.StringVal(clusterId),
+		FieldClusterSettingsEmptyNodesDeletionEnabled:      cty.True,
+		FieldClusterSettingsEmptyNodesDeletionDelaySeconds: cty.NumberIntVal(60),
+	}), 0)
+	state.ID = clusterId
+	diff, err := resource.Diff(ctx, state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		FieldClusterID: clusterId,
+		FieldClusterSettingsEmptyNodesDeletionDelaySeconds: 300,
+	}), provider)
+	r.NoError(err)
+	data, err := schema.InternalMap(resource.Schema).Data(state, diff)
+	r.NoError(err)
+
+	result := resource.UpdateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal(300, data.Get(FieldClusterSettingsEmptyNodesDeletionDelaySeconds))
+}
+
+func TestClusterSettingsResourceReadContextNotFound(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusNotFound, `{"message": "not found"}`), nil)
+
+	resource := resourceClusterSettings()
+	data := resource.TestResourceData()
+	data.SetId(clusterId)
+
+	result := resource.ReadContext(context.Background(), data, provider)
+	r.Nil(result)
+	r.Empty(data.Id())
+}
@@ -6,10 +6,10 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
-	FieldClusterID    = "cluster_id"
 	FieldClusterToken = "cluster_token"
 )
 
@@ -29,10 +29,11 @@ func resourceClusterToken() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			FieldClusterID: {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "CAST AI cluster id",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id",
 			},
 			FieldClusterToken: {
 				Type:        schema.TypeString,
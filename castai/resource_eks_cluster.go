@@ -8,6 +8,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
@@ -15,10 +16,12 @@ import (
 )
 
 const (
-	FieldEKSClusterName          = "name"
-	FieldEKSClusterAccountId     = "account_id"
-	FieldEKSClusterRegion        = "region"
-	FieldEKSClusterAssumeRoleArn = "assume_role_arn"
+	FieldEKSClusterName           = "name"
+	FieldEKSClusterAccountId      = "account_id"
+	FieldEKSClusterRegion         = "region"
+	FieldEKSClusterAssumeRoleArn  = "assume_role_arn"
+	FieldEKSClusterOrganizationId = "organization_id"
+	FieldEKSClusterHelmValues     = "helm_values"
 )
 
 func resourceEKSCluster() *schema.Resource {
@@ -28,7 +31,7 @@ func resourceEKSCluster() *schema.Resource {
 		UpdateContext: resourceCastaiEKSClusterUpdate,
 		DeleteContext: resourceCastaiClusterDelete,
 		Description:   "EKS cluster resource allows connecting an existing EKS cluster to CAST AI.",
-		CustomizeDiff: clusterTokenDiff,
+		CustomizeDiff: customdiff.All(clusterTokenDiff, clusterModeDiff(FieldEKSClusterAssumeRoleArn)),
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(5 * time.Minute),
@@ -80,6 +83,24 @@ func resourceEKSCluster() *schema.Resource {
 				Optional:    true,
 				Description: "Should CAST AI remove nodes managed by CAST AI on disconnect",
 			},
+			FieldClusterMode:             clusterModeSchema(FieldEKSClusterAssumeRoleArn),
+			FieldClusterResumeOnboarding: clusterResumeOnboardingSchema(FieldEKSClusterAssumeRoleArn),
+			FieldEKSClusterOrganizationId: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "CAST AI organization ID the cluster belongs to",
+			},
+			FieldEKSClusterHelmValues: {
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Values to install castai-agent and castai-cluster-controller helm charts with: " +
+					"`apiKey` (cluster token), `apiURL`, `clusterID`, `organizationID` and `provider`. " +
+					"castai-cluster-controller expects them under the `castai` key.",
+			},
 		},
 	}
 }
@@ -104,18 +125,22 @@ func resourceCastaiEKSClusterCreate(ctx context.Context, data *schema.ResourceDa
 		return diag.FromErr(checkErr)
 	}
 
-	clusterID := *resp.JSON200.Id
-	tkn, err := createClusterToken(ctx, client, clusterID)
+	// Cluster id is kept in state from now on, so a failure of later phases doesn't leave the cluster registered twice.
+	data.SetId(*resp.JSON200.Id)
+	tkn, err := createClusterToken(ctx, client, data.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		return clusterTokenFailed(data.Id(), err)
 	}
 	if err := data.Set(FieldClusterToken, tkn); err != nil {
 		return diag.FromErr(fmt.Errorf("setting cluster token: %w", err))
 	}
-	data.SetId(clusterID)
 
 	if err := updateClusterSettings(ctx, data, client); err != nil {
-		return diag.FromErr(err)
+		diags := clusterCredentialsFailed(data, FieldEKSClusterAssumeRoleArn, err)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceCastaiEKSClusterRead(ctx, data, meta)...)
 	}
 	log.Printf("[INFO] Cluster with id %q has been registered, don't forget to install castai-agent helm chart", data.Id())
 
@@ -145,6 +170,15 @@ func resourceCastaiEKSClusterRead(ctx context.Context, data *schema.ResourceData
 	if err := data.Set(FieldClusterCredentialsId, *resp.JSON200.CredentialsId); err != nil {
 		return diag.FromErr(fmt.Errorf("setting credentials id: %w", err))
 	}
+	if err := data.Set(FieldClusterMode, clusterMode(toString(resp.JSON200.CredentialsId))); err != nil {
+		return diag.FromErr(fmt.Errorf("setting mode: %w", err))
+	}
+	if err := data.Set(FieldEKSClusterOrganizationId, toString(resp.JSON200.OrganizationId)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting organization id: %w", err))
+	}
+	if err := data.Set(FieldEKSClusterHelmValues, clusterHelmValues(data, meta, "eks", toString(resp.JSON200.OrganizationId))); err != nil {
+		return diag.FromErr(fmt.Errorf("setting helm values: %w", err))
+	}
 
 	if eks := resp.JSON200.Eks; eks != nil {
 		if err := data.Set(FieldEKSClusterAccountId, toString(eks.AccountId)); err != nil {
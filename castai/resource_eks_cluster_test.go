@@ -27,6 +27,7 @@ func TestEKSClusterResourceReadContext(t *testing.T) {
 		api: &sdk.ClientWithResponses{
 			ClientInterface: mockClient,
 		},
+		apiURL: "https://api.cast.ai",
 	}
 
 	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
@@ -78,7 +79,15 @@ func TestEKSClusterResourceReadContext(t *testing.T) {
 account_id = 487609000000
 assume_role_arn = 
 credentials_id = 9b8d0456-177b-4a3d-b162-e68030d656aa
+helm_values.% = 5
+helm_values.apiKey = 
+helm_values.apiURL = https://api.cast.ai
+helm_values.clusterID = b6bfc074-a267-400f-b8f1-db0850c369b1
+helm_values.organizationID = 2836f775-aaaa-eeee-bbbb-3d3c29512692
+helm_values.provider = eks
+mode = full-access
 name = eks-cluster
+organization_id = 2836f775-aaaa-eeee-bbbb-3d3c29512692
 region = eu-central-1
 Tainted = false
 `, data.State().String())
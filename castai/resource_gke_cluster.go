@@ -9,6 +9,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
@@ -29,7 +30,7 @@ func resourceGKECluster() *schema.Resource {
 		ReadContext:   resourceCastaiGKEClusterRead,
 		UpdateContext: resourceCastaiGKEClusterUpdate,
 		DeleteContext: resourceCastaiClusterDelete,
-		CustomizeDiff: clusterTokenDiff,
+		CustomizeDiff: customdiff.All(clusterTokenDiff, clusterModeDiff(FieldGKEClusterCredentials)),
 		Description:   "GKE cluster resource allows connecting an existing GKE cluster to CAST AI.",
 
 		Timeouts: &schema.ResourceTimeout{
@@ -83,6 +84,8 @@ func resourceGKECluster() *schema.Resource {
 				Optional:    true,
 				Description: "Should CAST AI remove nodes managed by CAST.AI on disconnect",
 			},
+			FieldClusterMode:             clusterModeSchema(FieldGKEClusterCredentials),
+			FieldClusterResumeOnboarding: clusterResumeOnboardingSchema(FieldGKEClusterCredentials),
 		},
 	}
 }
@@ -119,18 +122,22 @@ func resourceCastaiGKEClusterCreate(ctx context.Context, data *schema.ResourceDa
 		return diag.FromErr(checkErr)
 	}
 
-	clusterID := *resp.JSON200.Id
-	tkn, err := createClusterToken(ctx, client, clusterID)
+	// Cluster id is kept in state from now on, so a failure of later phases doesn't leave the cluster registered twice.
+	data.SetId(*resp.JSON200.Id)
+	tkn, err := createClusterToken(ctx, client, data.Id())
 	if err != nil {
-		return diag.FromErr(err)
+		return clusterTokenFailed(data.Id(), err)
 	}
 	if err := data.Set(FieldClusterToken, tkn); err != nil {
 		return diag.FromErr(fmt.Errorf("setting cluster token: %w", err))
 	}
-	data.SetId(clusterID)
 
 	if err := updateGKEClusterSettings(ctx, data, client); err != nil {
-		return diag.FromErr(err)
+		diags := clusterCredentialsFailed(data, FieldGKEClusterCredentials, err)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceCastaiGKEClusterRead(ctx, data, meta)...)
 	}
 	log.Printf("[INFO] Cluster with id %q has been registered, don't forget to install castai-agent helm chart", data.Id())
 
@@ -160,6 +167,9 @@ func resourceCastaiGKEClusterRead(ctx context.Context, data *schema.ResourceData
 	if err := data.Set(FieldGKEClusterCredentialsId, toString(resp.JSON200.CredentialsId)); err != nil {
 		return diag.FromErr(fmt.Errorf("setting credentials id: %w", err))
 	}
+	if err := data.Set(FieldClusterMode, clusterMode(toString(resp.JSON200.CredentialsId))); err != nil {
+		return diag.FromErr(fmt.Errorf("setting mode: %w", err))
+	}
 	if GKE := resp.JSON200.Gke; GKE != nil {
 		if err := data.Set(FieldGKEClusterProjectId, toString(GKE.ProjectId)); err != nil {
 			return diag.FromErr(fmt.Errorf("setting project id: %w", err))
@@ -3,12 +3,17 @@ package castai
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
@@ -70,6 +75,7 @@ func TestGKEClusterResourceReadContext(t *testing.T) {
 	r.Equal(`ID = b6bfc074-a267-400f-b8f1-db0850c36gke
 credentials_id = 9b8d0456-177b-4a3d-b162-e68030d65GKE
 location = eu-central-1
+mode = full-access
 name = gke-cluster
 project_id = project-id
 Tainted = false
@@ -161,3 +167,158 @@ func TestGKEClusterResourceUpdateError(t *testing.T) {
 	r.True(result.HasError())
 	r.Equal("updating cluster configuration: expected status code 200, received: status=400 body={\"message\":\"Bad Request\", \"fieldViolations\":[{\"field\":\"credentials\",\"description\":\"error\"}]}", result[0].Summary)
 }
+
+func TestGKEClusterResourceCreateCredentialsError(t *testing.T) {
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c36gk3"
+
+	tests := map[string]struct {
+		resumeOnboarding bool
+		expectedSeverity diag.Severity
+	}{
+		"cluster is tainted": {
+			expectedSeverity: diag.Error,
+		},
+		"onboarding is resumed": {
+			resumeOnboarding: true,
+			expectedSeverity: diag.Warning,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			mockClient.EXPECT().
+				ExternalClusterAPIRegisterCluster(gomock.Any(), gomock.Any()).
+				Return(testJSONResponse(http.StatusOK, `{"id": "`+clusterID+`"}`), nil)
+			mockClient.EXPECT().
+				ExternalClusterAPICreateClusterToken(gomock.Any(), clusterID).
+				Return(testJSONResponse(http.StatusOK, `{"token": "token"}`), nil)
+			mockClient.EXPECT().
+				ExternalClusterAPIUpdateCluster(gomock.Any(), clusterID, gomock.Any()).
+				Return(testJSONResponse(http.StatusBadRequest, `{"message": "invalid credentials"}`), nil)
+			if tt.resumeOnboarding {
+				mockClient.EXPECT().
+					ExternalClusterAPIGetCluster(gomock.Any(), clusterID).
+					Return(testJSONResponse(http.StatusOK, `{"id": "`+clusterID+`", "name": "gke-cluster", "status": "ready"}`), nil)
+			}
+
+			resource := resourceGKECluster()
+			data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+				FieldGKEClusterName:          "gke-cluster",
+				FieldGKEClusterProjectId:     "project-id",
+				FieldGKEClusterLocation:      "europe-west1",
+				FieldGKEClusterCredentials:   "credentials",
+				FieldClusterResumeOnboarding: tt.resumeOnboarding,
+			})
+
+			result := resource.CreateContext(context.Background(), data, provider)
+			r.Len(result, 1)
+			r.Equal(tt.expectedSeverity, result[0].Severity)
+			r.Equal("Cluster b6bfc074-a267-400f-b8f1-db0850c36gk3 was registered, but updating its credentials failed", result[0].Summary)
+			r.Contains(result[0].Detail, "Completed onboarding phases: cluster registration, cluster token creation.")
+			r.Equal(clusterID, data.Id())
+			r.Equal("token", data.Get(FieldClusterToken))
+			r.Empty(data.Get(FieldGKEClusterCredentials))
+		})
+	}
+}
+
+// testAccGKEPreCheck skips the test when Google Cloud credentials aren't configured, so GKE acceptance tests only run
+// where a GKE cluster is available.
+func testAccGKEPreCheck(t *testing.T) {
+	if os.Getenv("GOOGLE_PROJECT") == "" {
+		t.Skip("GOOGLE_PROJECT must be set for GKE acceptance tests")
+	}
+	testAccPreCheck(t)
+}
+
+func TestAccResourceGKECluster(t *testing.T) {
+	rName := fmt.Sprintf("%v-gke-%v", ResourcePrefix, acctest.RandString(8))
+	resourceName := "castai_gke_cluster.test"
+	clusterName := "core-tf-acc"
+	location := "us-central1-c"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccGKEPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccGKEClusterConfig(rName, clusterName, location),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", clusterName),
+					resource.TestCheckResourceAttr(resourceName, "location", location),
+					resource.TestCheckResourceAttrSet(resourceName, "project_id"),
+					resource.TestCheckResourceAttrSet(resourceName, "credentials_id"),
+					resource.TestCheckResourceAttrSet(resourceName, "cluster_token"),
+				),
+			},
+		},
+		ExternalProviders: testAccGoogleProviders,
+	})
+}
+
+var testAccGoogleProviders = map[string]resource.ExternalProvider{
+	"google": {
+		Source:            "hashicorp/google",
+		VersionConstraint: "~> 4.0",
+	},
+}
+
+func testAccGKEClusterConfig(rName, clusterName, location string) string {
+	return ConfigCompose(testAccGoogleConfig(rName), fmt.Sprintf(`
+data "google_container_cluster" "test" {
+  name     = %[1]q
+  location = %[2]q
+}
+
+resource "castai_gke_cluster" "test" {
+  project_id       = data.google_client_config.current.project
+  location         = %[2]q
+  name             = %[1]q
+  credentials_json = base64decode(google_service_account_key.castai.private_key)
+}
+`, clusterName, location))
+}
+
+func testAccGoogleConfig(rName string) string {
+	return fmt.Sprintf(`
+provider "google" {}
+
+data "google_client_config" "current" {}
+
+data "castai_gke_user_policies" "gke" {}
+
+resource "google_service_account" "castai" {
+  account_id   = %[1]q
+  display_name = "Service account used by CAST AI acceptance tests"
+}
+
+resource "google_project_iam_custom_role" "castai" {
+  role_id     = replace(%[1]q, "-", "_")
+  title       = "Role used by CAST AI acceptance tests"
+  permissions = data.castai_gke_user_policies.gke.policy
+}
+
+resource "google_project_iam_member" "castai" {
+  for_each = toset([
+    google_project_iam_custom_role.castai.name,
+    "roles/container.developer",
+    "roles/iam.serviceAccountUser",
+  ])
+
+  project = data.google_client_config.current.project
+  role    = each.key
+  member  = "serviceAccount:${google_service_account.castai.email}"
+}
+
+resource "google_service_account_key" "castai" {
+  service_account_id = google_service_account.castai.name
+
+  depends_on = [google_project_iam_member.castai]
+}
+`, rName)
+}
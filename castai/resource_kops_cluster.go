@@ -0,0 +1,192 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	FieldKOPSClusterName        = "name"
+	FieldKOPSClusterCloud       = "cloud"
+	FieldKOPSClusterRegion      = "region"
+	FieldKOPSClusterStateStore  = "state_store"
+	FieldKOPSClusterCredentials = "credentials_json"
+)
+
+func resourceKOPSCluster() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCastaiKOPSClusterCreate,
+		ReadContext:   resourceCastaiKOPSClusterRead,
+		UpdateContext: resourceCastaiKOPSClusterUpdate,
+		DeleteContext: resourceCastaiClusterDelete,
+		CustomizeDiff: customdiff.All(clusterTokenDiff, clusterModeDiff(FieldKOPSClusterCredentials)),
+		Description:   "kOps cluster resource allows connecting an existing kOps cluster to CAST AI.",
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+			Delete: schema.DefaultTimeout(6 * time.Minute), // Cluster action timeout is 5 minutes.
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldKOPSClusterName: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "kOps cluster name",
+			},
+			FieldKOPSClusterCloud: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"aws", "gcp", "azure"}, false)),
+				Description:      "Cloud provider the cluster is running on. One of: aws, gcp, azure",
+			},
+			FieldKOPSClusterRegion: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "Cloud region of the cluster",
+			},
+			FieldKOPSClusterStateStore: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "kOps state store url, e.g. s3://my-kops-state-bucket",
+			},
+			FieldKOPSClusterCredentials: {
+				Type:             schema.TypeString,
+				Sensitive:        true,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
+				Description:      "Cloud credentials in JSON format used by CAST AI to manage the cluster",
+			},
+			FieldClusterCredentialsId: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "CAST AI credentials id for cluster",
+			},
+			FieldClusterToken: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "CAST.AI agent cluster token",
+			},
+			FieldDeleteNodesOnDisconnect: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Should CAST AI remove nodes managed by CAST.AI on disconnect",
+			},
+			FieldClusterMode:             clusterModeSchema(FieldKOPSClusterCredentials),
+			FieldClusterResumeOnboarding: clusterResumeOnboardingSchema(FieldKOPSClusterCredentials),
+		},
+	}
+}
+
+func resourceCastaiKOPSClusterCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	req := sdk.ExternalClusterAPIRegisterClusterJSONRequestBody{
+		Name: data.Get(FieldKOPSClusterName).(string),
+		Kops: &sdk.ExternalclusterV1KOPSClusterParams{
+			ClusterName: toPtr(data.Get(FieldKOPSClusterName).(string)),
+			Cloud:       toPtr(data.Get(FieldKOPSClusterCloud).(string)),
+			Region:      toPtr(data.Get(FieldKOPSClusterRegion).(string)),
+			StateStore:  toPtr(data.Get(FieldKOPSClusterStateStore).(string)),
+		},
+	}
+
+	log.Printf("[INFO] Registering new external cluster: %#v", req)
+
+	resp, err := client.ExternalClusterAPIRegisterClusterWithResponse(ctx, req)
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(checkErr)
+	}
+
+	// Cluster id is kept in state from now on, so a failure of later phases doesn't leave the cluster registered twice.
+	data.SetId(*resp.JSON200.Id)
+	tkn, err := createClusterToken(ctx, client, data.Id())
+	if err != nil {
+		return clusterTokenFailed(data.Id(), err)
+	}
+	if err := data.Set(FieldClusterToken, tkn); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster token: %w", err))
+	}
+
+	if err := updateClusterCredentials(ctx, data, client, FieldKOPSClusterCredentials); err != nil {
+		diags := clusterCredentialsFailed(data, FieldKOPSClusterCredentials, err)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceCastaiKOPSClusterRead(ctx, data, meta)...)
+	}
+	log.Printf("[INFO] Cluster with id %q has been registered, don't forget to install castai-agent helm chart", data.Id())
+
+	return resourceCastaiKOPSClusterRead(ctx, data, meta)
+}
+
+func resourceCastaiKOPSClusterRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	if data.Id() == "" {
+		log.Printf("[INFO] id is null not fetching anything.")
+		return nil
+	}
+
+	log.Printf("[INFO] Getting cluster information.")
+
+	resp, err := fetchClusterData(ctx, client, data.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if resp == nil {
+		data.SetId("")
+		return nil
+	}
+
+	if err := data.Set(FieldClusterCredentialsId, toString(resp.JSON200.CredentialsId)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting credentials id: %w", err))
+	}
+	if err := data.Set(FieldClusterMode, clusterMode(toString(resp.JSON200.CredentialsId))); err != nil {
+		return diag.FromErr(fmt.Errorf("setting mode: %w", err))
+	}
+	if KOPS := resp.JSON200.Kops; KOPS != nil {
+		if err := data.Set(FieldKOPSClusterName, toString(KOPS.ClusterName)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting cluster name: %w", err))
+		}
+		if err := data.Set(FieldKOPSClusterCloud, toString(KOPS.Cloud)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting cloud: %w", err))
+		}
+		if err := data.Set(FieldKOPSClusterRegion, toString(KOPS.Region)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting region: %w", err))
+		}
+		if err := data.Set(FieldKOPSClusterStateStore, toString(KOPS.StateStore)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting state store: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func resourceCastaiKOPSClusterUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	if err := updateClusterCredentials(ctx, data, client, FieldKOPSClusterCredentials); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceCastaiKOPSClusterRead(ctx, data, meta)
+}
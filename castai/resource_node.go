@@ -0,0 +1,338 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	FieldNodeInstanceType        = "instance_type"
+	FieldNodeZone                = "zone"
+	FieldNodeSubnetID            = "subnet_id"
+	FieldNodeConfigurationID     = "configuration_id"
+	FieldNodeSpot                = "spot"
+	FieldNodeVolumeSize          = "volume_size"
+	FieldNodeKubernetesLabels    = "kubernetes_labels"
+	FieldNodeKubernetesTaints    = "kubernetes_taints"
+	FieldNodeDrainTimeoutSeconds = "drain_timeout_seconds"
+	FieldNodeForceDelete         = "force_delete"
+	FieldNodeName                = "name"
+	FieldNodeInstanceID          = "instance_id"
+	FieldNodePrivateIP           = "private_ip"
+)
+
+func resourceNode() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNodeCreate,
+		ReadContext:   resourceNodeRead,
+		UpdateContext: resourceNodeUpdate,
+		DeleteContext: resourceNodeDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: nodeStateImporter,
+		},
+		Description: "Node added to the cluster by CAST AI outside of autoscaling, e.g. to pin static capacity. " +
+			"Use `count` or `for_each` to add several nodes. Changing node settings replaces the node.",
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldClusterID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id",
+			},
+			FieldNodeInstanceType: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "Instance type of the node",
+			},
+			FieldNodeZone: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "Availability zone of the node. Chosen by CAST AI when not set",
+			},
+			FieldNodeSubnetID: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Subnet of the node. Chosen by CAST AI when not set",
+			},
+			FieldNodeConfigurationID: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI node configuration id used to create the node. The default node configuration is used when not set",
+			},
+			FieldNodeSpot: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "Whether the node is a spot instance",
+			},
+			FieldNodeVolumeSize: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Root volume size of the node in GiB. Derived from the node configuration when not set",
+			},
+			FieldNodeKubernetesLabels: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Kubernetes labels of the node",
+			},
+			FieldNodeKubernetesTaints: {
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:             schema.TypeString,
+							Required:         true,
+							ForceNew:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+							Description:      "Key of the taint",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+							Description: "Value of the taint",
+						},
+						"effect": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ForceNew:         true,
+							Default:          taintEffectNoSchedule,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{taintEffectNoSchedule, "PreferNoSchedule", "NoExecute"}, false)),
+							Description:      "Effect of the taint",
+						},
+					},
+				},
+				Description: "Kubernetes taints of the node",
+			},
+			FieldNodeDrainTimeoutSeconds: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          600,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      "How long in seconds pods are drained from the node before it's deleted",
+			},
+			FieldNodeForceDelete: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete the node even when it can't be drained gracefully",
+			},
+			FieldNodeName: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Kubernetes name of the node",
+			},
+			FieldNodeInstanceID: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Cloud provider instance id of the node",
+			},
+			FieldNodePrivateIP: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Private IP address of the node",
+			},
+		},
+	}
+}
+
+func resourceNodeCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+	clusterID := d.Get(FieldClusterID).(string)
+
+	req := sdk.ExternalClusterAPIAddNodeJSONRequestBody{
+		InstanceType: d.Get(FieldNodeInstanceType).(string),
+	}
+	if v, ok := d.GetOk(FieldNodeZone); ok {
+		req.Zone = toPtr(v.(string))
+	}
+	if v, ok := d.GetOk(FieldNodeSubnetID); ok {
+		req.SubnetId = toPtr(v.(string))
+	}
+	if v, ok := d.GetOk(FieldNodeConfigurationID); ok {
+		req.ConfigurationId = toPtr(v.(string))
+	}
+	if v, ok := d.GetOk(FieldNodeSpot); ok {
+		req.SpotConfig = &sdk.ExternalclusterV1NodeSpotConfig{IsSpot: toPtr(v.(bool))}
+	}
+	if v, ok := d.GetOk(FieldNodeVolumeSize); ok {
+		req.Volume = &sdk.ExternalclusterV1NodeVolume{Size: toPtr(int32(v.(int)))}
+	}
+	if v, ok := d.GetOk(FieldNodeKubernetesLabels); ok {
+		req.KubernetesLabels = &sdk.ExternalclusterV1NodeConfig_KubernetesLabels{
+			AdditionalProperties: toStringMap(v.(map[string]interface{})),
+		}
+	}
+	if v, ok := d.GetOk(FieldNodeKubernetesTaints); ok {
+		req.KubernetesTaints = toPtr(lo.Map(v.([]interface{}), func(t interface{}, _ int) sdk.ExternalclusterV1Taint {
+			taint := t.(map[string]interface{})
+			return sdk.ExternalclusterV1Taint{
+				Key:    taint["key"].(string),
+				Value:  taint["value"].(string),
+				Effect: taint["effect"].(string),
+			}
+		}))
+	}
+
+	log.Printf("[INFO] Adding %s node to cluster %s", req.InstanceType, clusterID)
+
+	resp, err := client.ExternalClusterAPIAddNodeWithResponse(ctx, clusterID, req)
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(fmt.Errorf("adding node: %w", checkErr))
+	}
+
+	// Node is kept in state while it's provisioned, so it's deleted when provisioning fails.
+	d.SetId(resp.JSON200.NodeId)
+	if err := waitForClusterOperation(ctx, client, resp.JSON200.OperationId, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(fmt.Errorf("adding node %s: %w", d.Id(), err))
+	}
+
+	return resourceNodeRead(ctx, d, meta)
+}
+
+func resourceNodeRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+	clusterID := d.Get(FieldClusterID).(string)
+
+	resp, err := client.ExternalClusterAPIGetNodeWithResponse(ctx, clusterID, d.Id())
+	if err == nil && removeFromStateIfNotFound(d, "Node", isNotFound(resp)) {
+		return nil
+	}
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(fmt.Errorf("getting node: %w", checkErr))
+	}
+
+	// Subnet, volume size, labels and taints of the node aren't returned, they are kept as they were configured.
+	node := resp.JSON200
+	if err := d.Set(FieldNodeInstanceType, toString(node.InstanceType)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting instance type: %w", err))
+	}
+	if err := d.Set(FieldNodeZone, toString(node.Zone)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting zone: %w", err))
+	}
+	if err := d.Set(FieldNodeConfigurationID, toString(node.NodeConfigurationId)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting configuration id: %w", err))
+	}
+	if err := d.Set(FieldNodeSpot, lo.FromPtr(lo.FromPtr(node.SpotConfig).IsSpot)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting spot: %w", err))
+	}
+	if err := d.Set(FieldNodeName, toString(node.Name)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting name: %w", err))
+	}
+	if err := d.Set(FieldNodeInstanceID, toString(node.InstanceId)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting instance id: %w", err))
+	}
+	if err := d.Set(FieldNodePrivateIP, toString(lo.FromPtr(node.Network).PrivateIp)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting private ip: %w", err))
+	}
+
+	return nil
+}
+
+func resourceNodeUpdate(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// Node settings replace the node, only settings of its deletion are updated in place.
+	return nil
+}
+
+func resourceNodeDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+	clusterID := d.Get(FieldClusterID).(string)
+
+	resp, err := client.ExternalClusterAPIDeleteNodeWithResponse(ctx, clusterID, d.Id(), &sdk.ExternalClusterAPIDeleteNodeParams{
+		DrainTimeout: toPtr(strconv.Itoa(d.Get(FieldNodeDrainTimeoutSeconds).(int))),
+		ForceDelete:  toPtr(d.Get(FieldNodeForceDelete).(bool)),
+	})
+	if err == nil && isNotFound(resp) {
+		log.Printf("[WARN] Node %s is already deleted", d.Id())
+		return nil
+	}
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(fmt.Errorf("deleting node: %w", checkErr))
+	}
+
+	if err := waitForClusterOperation(ctx, client, toString(resp.JSON200.OperationId), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return diag.FromErr(fmt.Errorf("deleting node %s: %w", d.Id(), err))
+	}
+
+	return nil
+}
+
+// waitForClusterOperation waits until the long-running operation of the cluster is done and returns its error.
+func waitForClusterOperation(ctx context.Context, client *sdk.ClientWithResponses, operationID string, timeout time.Duration) error {
+	if operationID == "" {
+		return nil
+	}
+
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		resp, err := client.GetExternalClusterOperationWithResponse(ctx, operationID)
+		if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+			return retry.NonRetryableError(fmt.Errorf("getting operation %s: %w", operationID, checkErr))
+		}
+
+		operation := resp.JSON200
+		if !operation.Done {
+			return retry.RetryableError(fmt.Errorf("operation %s is in progress", operationID))
+		}
+		if operation.Error != nil {
+			return retry.NonRetryableError(fmt.Errorf("operation %s failed: %s: %s", operationID, operation.Error.Reason, operation.Error.Details))
+		}
+
+		return nil
+	})
+}
+
+func nodeStateImporter(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	ids := strings.Split(d.Id(), "/")
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" {
+		return nil, fmt.Errorf("expected import id with format: <cluster_id>/<node_id>, got: %q", d.Id())
+	}
+
+	if err := d.Set(FieldClusterID, ids[0]); err != nil {
+		return nil, fmt.Errorf("setting cluster id: %w", err)
+	}
+	if err := d.Set(FieldNodeDrainTimeoutSeconds, 600); err != nil {
+		return nil, fmt.Errorf("setting drain timeout: %w", err)
+	}
+	if err := d.Set(FieldNodeForceDelete, false); err != nil {
+		return nil, fmt.Errorf("setting force delete: %w", err)
+	}
+	d.SetId(ids[1])
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -2,17 +2,19 @@ package castai
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
 
 	"github.com/castai/terraform-provider-castai/castai/sdk"
 	castval "github.com/castai/terraform-provider-castai/castai/validation"
@@ -35,8 +37,19 @@ const (
 	FieldNodeConfigurationGKE              = "gke"
 )
 
+// nodeConfigurationInitScriptMaxSize is the maximum size of decoded init script, it's the limit of EC2 user data.
+const nodeConfigurationInitScriptMaxSize = 16 * 1024
+
+// nodeConfigurationCloudFields are mutually exclusive cloud specific blocks. Block names match provider types of clusters.
+var nodeConfigurationCloudFields = []string{
+	FieldNodeConfigurationAKS,
+	FieldNodeConfigurationEKS,
+	FieldNodeConfigurationKOPS,
+	FieldNodeConfigurationGKE,
+}
+
 func resourceNodeConfiguration() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceNodeConfigurationCreate,
 		ReadContext:   resourceNodeConfigurationRead,
 		UpdateContext: resourceNodeConfigurationUpdate,
@@ -55,10 +68,19 @@ func resourceNodeConfiguration() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			FieldClusterID: {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "CAST AI cluster id",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{FieldClusterID, FieldClusterName},
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id",
+			},
+			FieldClusterName: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "CAST AI cluster name. Can be used instead of `cluster_id`, the id is resolved when the node configuration is created",
 			},
 			FieldNodeConfigurationName: {
 				Type:             schema.TypeString,
@@ -75,7 +97,7 @@ func resourceNodeConfiguration() *schema.Resource {
 				Description:      "Disk to CPU ratio. Sets the number of GiBs to be added for every CPU on the node. Defaults to 0",
 			},
 			FieldNodeConfigurationSubnets: {
-				Type:     schema.TypeList,
+				Type:     schema.TypeSet,
 				Required: true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
@@ -83,9 +105,11 @@ func resourceNodeConfiguration() *schema.Resource {
 				Description: "Subnet ids to be used for provisioned nodes",
 			},
 			FieldNodeConfigurationSSHPublicKey: {
-				Type:             schema.TypeString,
-				Optional:         true,
-				Description:      "SSH public key to be used for provisioned nodes",
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "SSH public key to be used for provisioned nodes. Value should be base64 encoded. Changing the key updates " +
+					"the configuration in place, only nodes provisioned afterwards get the new key, so keep the old private key " +
+					"until existing nodes are replaced, e.g. by rebalancing",
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsBase64),
 			},
 			FieldNodeConfigurationImage: {
@@ -105,7 +129,7 @@ func resourceNodeConfiguration() *schema.Resource {
 			FieldNodeConfigurationInitScript: {
 				Type:             schema.TypeString,
 				Optional:         true,
-				Description:      "Init script to be run on your instance at launch. Should not contain any sensitive data. Value should be base64 encoded",
+				Description:      "Init script to be run on your instance at launch. Should not contain any sensitive data. Value should be base64 encoded. For EKS, decoded value should start with a shebang, `#cloud-config` or be a MIME multipart document and be at most 16 KiB",
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsBase64),
 			},
 			FieldNodeConfigurationContainerRuntime: {
@@ -132,13 +156,14 @@ func resourceNodeConfiguration() *schema.Resource {
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
 			},
 			FieldNodeConfigurationEKS: {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: lo.Without(nodeConfigurationCloudFields, FieldNodeConfigurationEKS),
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"security_groups": {
-							Type:     schema.TypeList,
+							Type:     schema.TypeSet,
 							Required: true,
 							MinItems: 1,
 							Elem: &schema.Schema{
@@ -191,9 +216,10 @@ func resourceNodeConfiguration() *schema.Resource {
 				},
 			},
 			FieldNodeConfigurationAKS: {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: lo.Without(nodeConfigurationCloudFields, FieldNodeConfigurationAKS),
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"max_pods_per_node": {
@@ -207,9 +233,10 @@ func resourceNodeConfiguration() *schema.Resource {
 				},
 			},
 			FieldNodeConfigurationKOPS: {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: lo.Without(nodeConfigurationCloudFields, FieldNodeConfigurationKOPS),
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"key_pair_id": {
@@ -222,9 +249,10 @@ func resourceNodeConfiguration() *schema.Resource {
 				},
 			},
 			FieldNodeConfigurationGKE: {
-				Type:     schema.TypeList,
-				Optional: true,
-				MaxItems: 1,
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: lo.Without(nodeConfigurationCloudFields, FieldNodeConfigurationGKE),
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"max_pods_per_node": {
@@ -247,23 +275,60 @@ func resourceNodeConfiguration() *schema.Resource {
 				},
 			},
 		},
-		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
-			return nil
+		CustomizeDiff: customdiff.All(
+			clusterNameForceNew,
+			nodeConfigurationInitScriptDiff,
+			nodeConfigurationCloudDiff,
+		),
+		SchemaVersion: 2,
+	}
+	// Attributes were only added since version 0, so its state is decoded with the current schema.
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: nodeConfigurationStateUpgradeV0,
+		},
+		{
+			Version: 1,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: nodeConfigurationStateUpgradeV1,
 		},
 	}
+
+	return r
+}
+
+// nodeConfigurationStateUpgradeV0 upgrades state written before schema versioning. Added attributes are either computed
+// or optional without defaults, so the state is kept as it is. Upgraders of later versions are chained after it.
+func nodeConfigurationStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	return rawState, nil
+}
+
+// nodeConfigurationStateUpgradeV1 drops duplicate values of lists which were converted to sets.
+func nodeConfigurationStateUpgradeV1(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	rawState[FieldNodeConfigurationSubnets] = uniqueStateValues(rawState[FieldNodeConfigurationSubnets], false)
+	for _, eks := range stateBlocks(rawState, FieldNodeConfigurationEKS) {
+		eks["security_groups"] = uniqueStateValues(eks["security_groups"], false)
+	}
+
+	return rawState, nil
 }
 
 func resourceNodeConfigurationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*ProviderConfig).api
 
-	clusterID := d.Get(FieldClusterID).(string)
+	clusterID, err := resolveClusterID(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	req := sdk.NodeConfigurationAPICreateConfigurationJSONRequestBody{
 		Name:         d.Get(FieldNodeConfigurationName).(string),
 		DiskCpuRatio: toPtr(int32(d.Get(FieldNodeConfigurationDiskCpuRatio).(int))),
 	}
 
 	if v, ok := d.GetOk(FieldNodeConfigurationSubnets); ok {
-		req.Subnets = toPtr(toStringList(v.([]interface{})))
+		req.Subnets = toPtr(toSortedStringList(v.(*schema.Set)))
 	}
 	if v, ok := d.GetOk(FieldNodeConfigurationImage); ok {
 		req.Image = toPtr(v.(string))
@@ -312,6 +377,7 @@ func resourceNodeConfigurationCreate(ctx context.Context, d *schema.ResourceData
 	}
 
 	resp, err := client.NodeConfigurationAPICreateConfigurationWithResponse(ctx, clusterID, req)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeConfigurations, clusterID))
 	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
 		return diag.FromErr(checkErr)
 	}
@@ -322,24 +388,15 @@ func resourceNodeConfigurationCreate(ctx context.Context, d *schema.ResourceData
 }
 
 func resourceNodeConfigurationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ProviderConfig).api
-
 	clusterID := d.Get(FieldClusterID).(string)
-	resp, err := client.NodeConfigurationAPIGetConfigurationWithResponse(ctx, clusterID, d.Id())
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if !d.IsNewResource() && resp.StatusCode() == http.StatusNotFound {
-		log.Printf("[WARN] Node configuration (%s) not found, removing from state", d.Id())
-		d.SetId("")
+	nodeConfig, err := getNodeConfiguration(ctx, meta, clusterID, d.Id())
+	if removeFromStateIfNotFound(d, "Node configuration", isNotFoundErr(err)) {
 		return nil
 	}
-	if err := sdk.CheckOKResponse(resp, err); err != nil {
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	nodeConfig := resp.JSON200
-
 	if err := d.Set(FieldNodeConfigurationName, nodeConfig.Name); err != nil {
 		return diag.FromErr(fmt.Errorf("setting name: %w", err))
 	}
@@ -427,7 +484,7 @@ func resourceNodeConfigurationUpdate(ctx context.Context, d *schema.ResourceData
 	}
 
 	if v, ok := d.GetOk(FieldNodeConfigurationSubnets); ok {
-		req.Subnets = toPtr(toStringList(v.([]interface{})))
+		req.Subnets = toPtr(toSortedStringList(v.(*schema.Set)))
 	}
 	if v, ok := d.GetOk(FieldNodeConfigurationImage); ok {
 		req.Image = toPtr(v.(string))
@@ -476,6 +533,7 @@ func resourceNodeConfigurationUpdate(ctx context.Context, d *schema.ResourceData
 	}
 
 	resp, err := client.NodeConfigurationAPIUpdateConfigurationWithResponse(ctx, clusterID, d.Id(), req)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeConfigurations, clusterID))
 	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
 		return diag.FromErr(checkErr)
 	}
@@ -492,7 +550,7 @@ func resourceNodeConfigurationDelete(ctx context.Context, d *schema.ResourceData
 		return diag.FromErr(err)
 	}
 
-	if resp.StatusCode() == http.StatusNotFound {
+	if isNotFound(resp) {
 		log.Printf("[DEBUG] Node configuration (%s) not found, skipping delete", d.Id())
 		return nil
 	}
@@ -507,6 +565,7 @@ func resourceNodeConfigurationDelete(ctx context.Context, d *schema.ResourceData
 	}
 
 	del, err := client.NodeConfigurationAPIDeleteConfigurationWithResponse(ctx, clusterID, d.Id())
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeConfigurations, clusterID))
 	if err := sdk.CheckOKResponse(del, err); err != nil {
 		return diag.FromErr(err)
 	}
@@ -529,8 +588,8 @@ func toEKSConfig(obj map[string]interface{}) *sdk.NodeconfigV1EKSConfig {
 	if v, ok := obj["key_pair_id"].(string); ok && v != "" {
 		out.KeyPairId = toPtr(v)
 	}
-	if v, ok := obj["security_groups"].([]interface{}); ok && len(v) > 0 {
-		out.SecurityGroups = toPtr(toStringList(v))
+	if v, ok := obj["security_groups"].(*schema.Set); ok && v.Len() > 0 {
+		out.SecurityGroups = toPtr(toSortedStringList(v))
 	}
 	if v, ok := obj["volume_type"].(string); ok && v != "" {
 		out.VolumeType = toPtr(v)
@@ -548,6 +607,76 @@ func toEKSConfig(obj map[string]interface{}) *sdk.NodeconfigV1EKSConfig {
 	return out
 }
 
+// nodeConfigurationCloudDiff rejects cloud specific block which doesn't match provider type of the cluster, e.g. `gke`
+// block of EKS cluster. The check is skipped when the cluster is not known yet or it can't be fetched.
+func nodeConfigurationCloudDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if isOffline(meta) {
+		return nil
+	}
+	if d.Id() != "" && !d.HasChanges(nodeConfigurationCloudFields...) {
+		return nil
+	}
+	field, ok := lo.Find(nodeConfigurationCloudFields, func(field string) bool {
+		v, _ := d.Get(field).([]interface{})
+		return len(v) > 0
+	})
+	if !ok {
+		return nil
+	}
+
+	clusterID, _ := d.Get(FieldClusterID).(string)
+	if clusterID == "" || !d.NewValueKnown(FieldClusterID) {
+		return nil
+	}
+
+	client := meta.(*ProviderConfig).api
+	resp, err := client.ExternalClusterAPIGetClusterWithResponse(ctx, clusterID)
+	if err != nil || resp.JSON200 == nil {
+		log.Printf("[WARN] Skipping cloud block validation, cluster %q can't be fetched: %v", clusterID, err)
+		return nil
+	}
+
+	providerType := strings.ToLower(lo.FromPtr(resp.JSON200.ProviderType))
+	if lo.Contains(nodeConfigurationCloudFields, providerType) && providerType != field {
+		return fmt.Errorf("%q block can't be used with %s cluster %q, use %q block instead", field, providerType, clusterID, providerType)
+	}
+
+	return nil
+}
+
+// nodeConfigurationInitScriptHeaders are prefixes of EC2 user data formats accepted for EKS: shell scripts, cloud-init
+// configs and MIME multipart documents, e.g. nodeadm configuration of AL2023.
+var nodeConfigurationInitScriptHeaders = []string{"#!", "#cloud-config", "mime-version:", "content-type: multipart/"}
+
+// nodeConfigurationInitScriptDiff rejects EKS init scripts which the API would reject after apply: scripts over the EC2
+// user data size limit and scripts which are not in one of the user data formats.
+func nodeConfigurationInitScriptDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	v, _ := d.Get(FieldNodeConfigurationInitScript).(string)
+	if v == "" || !d.NewValueKnown(FieldNodeConfigurationInitScript) {
+		return nil
+	}
+
+	script, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		return fmt.Errorf("%s must be base64 encoded: %w", FieldNodeConfigurationInitScript, err)
+	}
+	if eks, _ := d.Get(FieldNodeConfigurationEKS).([]interface{}); len(eks) == 0 {
+		return nil
+	}
+	if len(script) > nodeConfigurationInitScriptMaxSize {
+		return fmt.Errorf("%s must be at most %d bytes when decoded, got %d bytes",
+			FieldNodeConfigurationInitScript, nodeConfigurationInitScriptMaxSize, len(script))
+	}
+
+	lower := strings.ToLower(string(script))
+	if !lo.SomeBy(nodeConfigurationInitScriptHeaders, func(prefix string) bool { return strings.HasPrefix(lower, prefix) }) {
+		return fmt.Errorf("%s must start with a shebang, e.g. \"#!/bin/bash\", \"#cloud-config\" or be a MIME multipart document",
+			FieldNodeConfigurationInitScript)
+	}
+
+	return nil
+}
+
 func flattenEKSConfig(config *sdk.NodeconfigV1EKSConfig) []map[string]interface{} {
 	if config == nil {
 		return nil
@@ -662,6 +791,50 @@ func flattenGKEConfig(config *sdk.NodeconfigV1GKEConfig) []map[string]interface{
 	return []map[string]interface{}{m}
 }
 
+// listNodeConfigurations lists node configurations of the cluster. Successful responses are cached by the provider, so
+// refresh of many configurations of the same cluster makes a single list call.
+func listNodeConfigurations(ctx context.Context, meta interface{}, clusterID string) (*sdk.NodeConfigurationAPIListConfigurationsResponse, error) {
+	config := meta.(*ProviderConfig)
+
+	return getCachedResponse(config.responses, listCacheKey(listCacheEndpointNodeConfigurations, clusterID), func() (*sdk.NodeConfigurationAPIListConfigurationsResponse, error) {
+		return fetchWithRetry(ctx, func() (*sdk.NodeConfigurationAPIListConfigurationsResponse, error) {
+			return config.api.NodeConfigurationAPIListConfigurationsWithResponse(ctx, clusterID)
+		})
+	})
+}
+
+// getNodeConfiguration returns the node configuration of the cluster. When the provider caches responses, configuration
+// is looked up in the cached list, otherwise it's fetched directly. Returns notFoundError when configuration doesn't exist.
+func getNodeConfiguration(ctx context.Context, meta interface{}, clusterID, id string) (*sdk.NodeconfigV1NodeConfiguration, error) {
+	config := meta.(*ProviderConfig)
+
+	if config.responses != nil {
+		resp, err := listNodeConfigurations(ctx, meta, clusterID)
+		if checkErr := sdk.CheckOKResponse(resp, err); checkErr == nil {
+			item, ok := lo.Find(lo.FromPtr(resp.JSON200.Items), func(c sdk.NodeconfigV1NodeConfiguration) bool {
+				return lo.FromPtr(c.Id) == id
+			})
+			if ok {
+				return &item, nil
+			}
+		}
+		// Fall back to fetching the configuration, so missing configurations and list errors are reported by the API.
+	}
+
+	resp, err := config.api.NodeConfigurationAPIGetConfigurationWithResponse(ctx, clusterID, id)
+	if err != nil {
+		return nil, err
+	}
+	if isNotFound(resp) {
+		return nil, newNotFoundError("node configuration %q of cluster %q not found at CAST AI", id, clusterID)
+	}
+	if err := sdk.CheckOKResponse(resp, err); err != nil {
+		return nil, err
+	}
+
+	return resp.JSON200, nil
+}
+
 func nodeConfigStateImporter(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
 	ids := strings.Split(d.Id(), "/")
 	if len(ids) != 2 || ids[0] == "" || ids[1] == "" {
@@ -680,8 +853,7 @@ func nodeConfigStateImporter(ctx context.Context, d *schema.ResourceData, meta i
 	}
 
 	// Find node configuration ID based on provided name.
-	client := meta.(*ProviderConfig).api
-	resp, err := client.NodeConfigurationAPIListConfigurationsWithResponse(ctx, clusterID)
+	resp, err := listNodeConfigurations(ctx, meta, clusterID)
 	if err != nil {
 		return nil, err
 	}
@@ -3,13 +3,12 @@ package castai
 import (
 	"context"
 	"fmt"
-	"log"
-	"net/http"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
 
 	"github.com/castai/terraform-provider-castai/castai/sdk"
 )
@@ -28,10 +27,11 @@ func resourceNodeConfigurationDefault() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			FieldClusterID: {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "CAST AI cluster id",
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id",
 			},
 			"configuration_id": {
 				Type:             schema.TypeString,
@@ -50,6 +50,8 @@ func resourceNodeConfigurationDefaultCreate(ctx context.Context, d *schema.Resou
 	id := d.Get("configuration_id").(string)
 
 	resp, err := client.NodeConfigurationAPISetDefaultWithResponse(ctx, clusterID, id)
+	// Previous default configuration is changed as well.
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeConfigurations, clusterID))
 	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
 		return diag.FromErr(checkErr)
 	}
@@ -60,29 +62,32 @@ func resourceNodeConfigurationDefaultCreate(ctx context.Context, d *schema.Resou
 }
 
 func resourceNodeConfigurationDefaultRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	client := meta.(*ProviderConfig).api
 	clusterID := d.Get(FieldClusterID).(string)
-	id := d.Get("configuration_id").(string)
 
-	resp, err := client.NodeConfigurationAPIGetConfigurationWithResponse(ctx, clusterID, id)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-
-	if !d.IsNewResource() && resp.StatusCode() == http.StatusNotFound {
-		log.Printf("[WARN] Node configuration (%s) not found, removing from state", d.Id())
-		d.SetId("")
+	resp, err := listNodeConfigurations(ctx, meta, clusterID)
+	// Configurations of deleted clusters are not found.
+	if removeFromStateIfNotFound(d, "Node configuration", err == nil && isNotFound(resp)) {
 		return nil
 	}
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(checkErr)
+	}
 
-	if err := sdk.CheckOKResponse(resp, err); err != nil {
-		return diag.FromErr(err)
+	items := lo.FromPtr(resp.JSON200.Items)
+	_, ok := lo.Find(items, func(c sdk.NodeconfigV1NodeConfiguration) bool {
+		return lo.FromPtr(c.Id) == d.Id()
+	})
+	if removeFromStateIfNotFound(d, "Node configuration", !ok) {
+		return nil
 	}
 
-	configID := resp.JSON200.Id
-	if !*resp.JSON200.Default {
-		// If configuration is no longer default, we should trigger state change.
-		configID = nil
+	// Default configuration can be changed outside of Terraform, the actual one is read so that the plan shows which
+	// configuration is default now.
+	var configID *string
+	if current, ok := lo.Find(items, func(c sdk.NodeconfigV1NodeConfiguration) bool {
+		return lo.FromPtr(c.Default)
+	}); ok {
+		configID = current.Id
 	}
 	if err := d.Set("configuration_id", configID); err != nil {
 		return diag.FromErr(fmt.Errorf("setting configuration id: %w", err))
@@ -0,0 +1,80 @@
+package castai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestNodeConfigurationDefaultResourceReadContext(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	configId := "8a0c5e4b-2f29-4c4f-9b5f-2c5f54fe2b3d"
+	tests := map[string]struct {
+		status        int
+		response      string
+		expectedState string
+	}{
+		"configuration is default": {
+			response: `{"items": [{"id": "8a0c5e4b-2f29-4c4f-9b5f-2c5f54fe2b3d", "default": true}, {"id": "e2f6f8a6-67c4-4ef7-8d0c-6d1a5d2a0c11", "default": false}]}`,
+			expectedState: `ID = 8a0c5e4b-2f29-4c4f-9b5f-2c5f54fe2b3d
+cluster_id = b6bfc074-a267-400f-b8f1-db0850c369b1
+configuration_id = 8a0c5e4b-2f29-4c4f-9b5f-2c5f54fe2b3d
+Tainted = false
+`,
+		},
+		"other configuration was made default": {
+			response: `{"items": [{"id": "8a0c5e4b-2f29-4c4f-9b5f-2c5f54fe2b3d", "default": false}, {"id": "e2f6f8a6-67c4-4ef7-8d0c-6d1a5d2a0c11", "default": true}]}`,
+			expectedState: `ID = 8a0c5e4b-2f29-4c4f-9b5f-2c5f54fe2b3d
+cluster_id = b6bfc074-a267-400f-b8f1-db0850c369b1
+configuration_id = e2f6f8a6-67c4-4ef7-8d0c-6d1a5d2a0c11
+Tainted = false
+`,
+		},
+		"configuration was removed": {
+			response:      `{"items": [{"id": "e2f6f8a6-67c4-4ef7-8d0c-6d1a5d2a0c11", "default": true}]}`,
+			expectedState: `<not created>`,
+		},
+		"cluster was deleted": {
+			status:        http.StatusNotFound,
+			response:      `{"message": "cluster not found"}`,
+			expectedState: `<not created>`,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			status := tt.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			mockClient.EXPECT().
+				NodeConfigurationAPIListConfigurations(gomock.Any(), clusterId).
+				Return(testJSONResponse(status, tt.response), nil)
+
+			resource := resourceNodeConfigurationDefault()
+			state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+				FieldClusterID:     cty.StringVal(clusterId),
+				"configuration_id": cty.StringVal(configId),
+			}), 0)
+			state.ID = configId
+
+			data := resource.Data(state)
+			result := resource.ReadContext(context.Background(), data, provider)
+			r.Nil(result)
+			r.Equal(tt.expectedState, data.State().String())
+		})
+	}
+}
@@ -1,17 +1,541 @@
 package castai
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
 )
 
+const testNodeConfigurationResponse = `
+{
+  "id": "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a",
+  "name": "default",
+  "default": false,
+  "diskCpuRatio": 25,
+  "subnets": ["subnet-1", "subnet-2"],
+  "image": "ami-123",
+  "initScript": null,
+  "sshPublicKey": null,
+  "containerRuntime": "CONTAINERD",
+  "dockerConfig": null,
+  "kubeletConfig": {"registryBurst": 20},
+  "tags": {"env": "dev"},
+  "eks": {
+    "instanceProfileArn": "arn:aws:iam::123456789012:instance-profile/castai",
+    "securityGroups": ["sg-1"],
+    "dnsClusterIp": "10.100.0.10",
+    "imdsV1": true,
+    "volumeType": "gp3",
+    "volumeIops": 3000,
+    "volumeThroughput": 125
+  }
+}`
+
+func TestNodeConfigurationResourceReadContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+	mockClient.EXPECT().
+		NodeConfigurationAPIGetConfiguration(gomock.Any(), clusterId, id).
+		Return(testJSONResponse(http.StatusOK, testNodeConfigurationResponse), nil)
+
+	resource := resourceNodeConfiguration()
+	state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+		FieldClusterID: cty.StringVal(clusterId),
+	}), 0)
+	state.ID = id
+
+	data := resource.Data(state)
+	result := resource.ReadContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal(`ID = c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a
+aks.# = 0
+cluster_id = b6bfc074-a267-400f-b8f1-db0850c369b1
+container_runtime = CONTAINERD
+disk_cpu_ratio = 25
+eks.# = 1
+eks.0.dns_cluster_ip = 10.100.0.10
+eks.0.imds_v1 = true
+eks.0.instance_profile_arn = arn:aws:iam::123456789012:instance-profile/castai
+eks.0.key_pair_id = 
+eks.0.security_groups.# = 1
+eks.0.security_groups.2573375901 = sg-1
+eks.0.volume_iops = 3000
+eks.0.volume_throughput = 125
+eks.0.volume_type = gp3
+gke.# = 0
+image = ami-123
+init_script = 
+kops.# = 0
+kubelet_config = {"registryBurst":20}
+name = default
+ssh_public_key = 
+subnets.# = 2
+subnets.1008853180 = subnet-2
+subnets.386708351 = subnet-1
+tags.% = 1
+tags.env = dev
+Tainted = false
+`, data.State().String())
+}
+
+func TestNodeConfigurationResourceReadContextNotFound(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+	mockClient.EXPECT().
+		NodeConfigurationAPIGetConfiguration(gomock.Any(), clusterId, id).
+		Return(testJSONResponse(http.StatusNotFound, `{"message": "not found"}`), nil)
+
+	resource := resourceNodeConfiguration()
+	state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+		FieldClusterID: cty.StringVal(clusterId),
+	}), 0)
+	state.ID = id
+
+	data := resource.Data(state)
+	result := resource.ReadContext(ctx, data, provider)
+	r.Nil(result)
+	r.Empty(data.Id())
+}
+
+func TestNodeConfigurationResourceReadContextCachesList(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{
+		api:       &sdk.ClientWithResponses{ClientInterface: mockClient},
+		responses: newResponseCache(listCacheTTL),
+	}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+	deletedID := "0a1b2c3d-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+	mockClient.EXPECT().
+		NodeConfigurationAPIListConfigurations(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [`+testNodeConfigurationResponse+`]}`), nil)
+	// Configuration missing from the list is fetched directly to confirm it's gone.
+	mockClient.EXPECT().
+		NodeConfigurationAPIGetConfiguration(gomock.Any(), clusterId, deletedID).
+		Return(testJSONResponse(http.StatusNotFound, `{"message": "not found"}`), nil)
+
+	resource := resourceNodeConfiguration()
+	read := func(id string) *schema.ResourceData {
+		state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+			FieldClusterID: cty.StringVal(clusterId),
+		}), 0)
+		state.ID = id
+		data := resource.Data(state)
+		r.Nil(resource.ReadContext(ctx, data, provider))
+		return data
+	}
+
+	data := read(id)
+	r.Equal(id, data.Id())
+	r.Equal("default", data.Get(FieldNodeConfigurationName))
+	r.Equal(id, read(id).Id())
+	r.Empty(read(deletedID).Id())
+}
+
+func TestNodeConfigurationResourceCreateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+
+	mockClient.EXPECT().
+		NodeConfigurationAPICreateConfiguration(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.NodeConfigurationAPICreateConfigurationJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal("default", req.Name)
+			r.Equal(int32(25), lo.FromPtr(req.DiskCpuRatio))
+			r.Equal([]string{"subnet-1", "subnet-2"}, lo.FromPtr(req.Subnets))
+			r.Equal(map[string]string{"env": "dev"}, req.Tags.AdditionalProperties)
+			r.Equal(map[string]interface{}{"registryBurst": float64(20)}, lo.FromPtr(req.KubeletConfig))
+			r.NotNil(req.Eks)
+			r.Equal("arn:aws:iam::123456789012:instance-profile/castai", req.Eks.InstanceProfileArn)
+			r.Equal([]string{"sg-1"}, lo.FromPtr(req.Eks.SecurityGroups))
+			r.Equal(int32(3000), lo.FromPtr(req.Eks.VolumeIops))
+			r.Nil(req.Gke)
+			r.Nil(req.Aks)
+			r.Nil(req.Kops)
+
+			return testJSONResponse(http.StatusOK, fmt.Sprintf(`{"id": %q}`, id)), nil
+		})
+	mockClient.EXPECT().
+		NodeConfigurationAPIGetConfiguration(gomock.Any(), clusterId, id).
+		Return(testJSONResponse(http.StatusOK, testNodeConfigurationResponse), nil)
+
+	resource := resourceNodeConfiguration()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID:                      clusterId,
+		FieldNodeConfigurationName:          "default",
+		FieldNodeConfigurationDiskCpuRatio:  25,
+		FieldNodeConfigurationSubnets:       []interface{}{"subnet-1", "subnet-2"},
+		FieldNodeConfigurationTags:          map[string]interface{}{"env": "dev"},
+		FieldNodeConfigurationKubeletConfig: `{"registryBurst": 20}`,
+		FieldNodeConfigurationEKS: []interface{}{
+			map[string]interface{}{
+				"instance_profile_arn": "arn:aws:iam::123456789012:instance-profile/castai",
+				"security_groups":      []interface{}{"sg-1"},
+				"volume_iops":          3000,
+			},
+		},
+	})
+
+	result := resource.CreateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal(id, data.Id())
+	r.Equal("gp3", data.Get("eks.0.volume_type"))
+}
+
+func TestNodeConfigurationResourceCreateContextClusterName(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+
+	mockClient.EXPECT().
+		ExternalClusterAPIListClusters(gomock.Any(), gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, fmt.Sprintf(`{"items": [{"id": "other", "name": "staging"}, {"id": %q, "name": "production"}]}`, clusterId)), nil)
+	mockClient.EXPECT().
+		NodeConfigurationAPICreateConfiguration(gomock.Any(), clusterId, gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, fmt.Sprintf(`{"id": %q}`, id)), nil)
+	mockClient.EXPECT().
+		NodeConfigurationAPIGetConfiguration(gomock.Any(), clusterId, id).
+		Return(testJSONResponse(http.StatusOK, testNodeConfigurationResponse), nil)
+
+	resource := resourceNodeConfiguration()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterName:                   "production",
+		FieldNodeConfigurationName:         "default",
+		FieldNodeConfigurationDiskCpuRatio: 25,
+	})
+
+	result := resource.CreateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal(id, data.Id())
+	r.Equal(clusterId, data.Get(FieldClusterID))
+	r.Equal("production", data.Get(FieldClusterName))
+}
+
+func TestNodeConfigurationResourceUpdateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+
+	mockClient.EXPECT().
+		NodeConfigurationAPIUpdateConfiguration(gomock.Any(), clusterId, id, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, req sdk.NodeConfigurationAPIUpdateConfigurationJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal(int32(25), lo.FromPtr(req.DiskCpuRatio))
+			r.Equal("ami-123", lo.FromPtr(req.Image))
+			r.Equal(map[string]string{"env": "dev"}, req.Tags.AdditionalProperties)
+
+			return testJSONResponse(http.StatusOK, testNodeConfigurationResponse), nil
+		})
+	mockClient.EXPECT().
+		NodeConfigurationAPIGetConfiguration(gomock.Any(), clusterId, id).
+		Return(testJSONResponse(http.StatusOK, testNodeConfigurationResponse), nil)
+
+	resource := resourceNodeConfiguration()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID:                     clusterId,
+		FieldNodeConfigurationName:         "default",
+		FieldNodeConfigurationDiskCpuRatio: 25,
+		FieldNodeConfigurationImage:        "ami-123",
+		FieldNodeConfigurationTags:         map[string]interface{}{"env": "dev"},
+	})
+	data.SetId(id)
+
+	result := resource.UpdateContext(ctx, data, provider)
+	r.Nil(result)
+}
+
+func TestNodeConfigurationResourceDeleteContext(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+
+	tests := map[string]struct {
+		getResponse  *http.Response
+		expectDelete bool
+	}{
+		"should delete node configuration": {
+			getResponse:  testJSONResponse(http.StatusOK, `{"default": false}`),
+			expectDelete: true,
+		},
+		"should skip delete of default node configuration": {
+			getResponse:  testJSONResponse(http.StatusOK, `{"default": true}`),
+			expectDelete: false,
+		},
+		"should skip delete of missing node configuration": {
+			getResponse:  testJSONResponse(http.StatusNotFound, `{}`),
+			expectDelete: false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			mockClient.EXPECT().
+				NodeConfigurationAPIGetConfiguration(gomock.Any(), clusterId, id).
+				Return(tt.getResponse, nil)
+			if tt.expectDelete {
+				mockClient.EXPECT().
+					NodeConfigurationAPIDeleteConfiguration(gomock.Any(), clusterId, id).
+					Return(testJSONResponse(http.StatusOK, `{}`), nil)
+			}
+
+			resource := resourceNodeConfiguration()
+			data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+				FieldClusterID:             clusterId,
+				FieldNodeConfigurationName: "default",
+			})
+			data.SetId(id)
+
+			result := resource.DeleteContext(context.Background(), data, provider)
+			r.Nil(result)
+		})
+	}
+}
+
+func TestNodeConfigurationResourceImport(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	id := "c7a2e6a5-9bf6-4c1b-8a54-5f4fbb0d5f3a"
+
+	mockClient.EXPECT().
+		NodeConfigurationAPIListConfigurations(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, fmt.Sprintf(`{"items": [{"id": "other", "name": "other"}, {"id": %q, "name": "default"}]}`, id)), nil)
+
+	resource := resourceNodeConfiguration()
+
+	data := resource.Data(&terraform.InstanceState{ID: clusterId + "/default"})
+	result, err := resource.Importer.StateContext(ctx, data, provider)
+	r.NoError(err)
+	r.Len(result, 1)
+	r.Equal(id, result[0].Id())
+	r.Equal(clusterId, result[0].Get(FieldClusterID))
+
+	// Import by ID doesn't call the API.
+	data = resource.Data(&terraform.InstanceState{ID: clusterId + "/" + id})
+	result, err = resource.Importer.StateContext(ctx, data, provider)
+	r.NoError(err)
+	r.Equal(id, result[0].Id())
+
+	data = resource.Data(&terraform.InstanceState{ID: "invalid"})
+	_, err = resource.Importer.StateContext(ctx, data, provider)
+	r.Error(err)
+}
+
+func testJSONResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		Header:     map[string][]string{"Content-Type": {"json"}},
+	}
+}
+
+func TestNodeConfigurationResourceInitScriptDiff(t *testing.T) {
+	eks := []any{map[string]any{
+		"instance_profile_arn": "arn:aws:iam::123456789012:instance-profile/castai",
+		"security_groups":      []any{"sg-1"},
+	}}
+	gke := []any{map[string]any{"max_pods_per_node": 64}}
+
+	tests := map[string]struct {
+		cloud       map[string]any
+		script      string
+		expectedErr string
+	}{
+		"shell script": {
+			cloud:  map[string]any{FieldNodeConfigurationEKS: eks},
+			script: base64.StdEncoding.EncodeToString([]byte("#!/bin/bash\necho hello")),
+		},
+		"cloud-config": {
+			cloud:  map[string]any{FieldNodeConfigurationEKS: eks},
+			script: base64.StdEncoding.EncodeToString([]byte("#cloud-config\nruncmd:\n  - echo hello")),
+		},
+		"nodeadm MIME multipart": {
+			cloud: map[string]any{FieldNodeConfigurationEKS: eks},
+			script: base64.StdEncoding.EncodeToString([]byte("MIME-Version: 1.0\n" +
+				"Content-Type: multipart/mixed; boundary=\"BOUNDARY\"\n\n--BOUNDARY\n" +
+				"Content-Type: application/node.eks.aws\n\napiVersion: node.eks.aws/v1alpha1\nkind: NodeConfig\n--BOUNDARY--")),
+		},
+		"script without shebang": {
+			cloud:       map[string]any{FieldNodeConfigurationEKS: eks},
+			script:      base64.StdEncoding.EncodeToString([]byte("echo hello")),
+			expectedErr: "init_script must start with a shebang",
+		},
+		"script over size limit": {
+			cloud:       map[string]any{FieldNodeConfigurationEKS: eks},
+			script:      base64.StdEncoding.EncodeToString([]byte("#!/bin/bash\n" + strings.Repeat("#", nodeConfigurationInitScriptMaxSize))),
+			expectedErr: "init_script must be at most 16384 bytes when decoded",
+		},
+		"not base64 encoded": {
+			cloud:       map[string]any{FieldNodeConfigurationEKS: eks},
+			script:      "#!/bin/bash",
+			expectedErr: "init_script",
+		},
+		"GKE script is not checked": {
+			cloud:  map[string]any{FieldNodeConfigurationGKE: gke},
+			script: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("echo hello\n", nodeConfigurationInitScriptMaxSize))),
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+
+			config := map[string]any{
+				FieldClusterID:                   "b6bfc074-a267-400f-b8f1-db0850c369b1",
+				FieldNodeConfigurationName:       "test",
+				FieldNodeConfigurationSubnets:    []any{"subnet-1"},
+				FieldNodeConfigurationInitScript: tt.script,
+			}
+			for k, v := range tt.cloud {
+				config[k] = v
+			}
+
+			resource := resourceNodeConfiguration()
+			// Offline provider skips validation of cloud blocks against the cluster.
+			_, err := resource.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), &ProviderConfig{offline: true})
+			if tt.expectedErr == "" {
+				r.NoError(err)
+				return
+			}
+			r.Error(err)
+			r.Contains(err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestNodeConfigurationResourceCloudDiff(t *testing.T) {
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	tests := map[string]struct {
+		blocks      map[string]any
+		expectedErr string
+	}{
+		"block of cluster provider": {
+			blocks: map[string]any{
+				FieldNodeConfigurationGKE: []any{map[string]any{"max_pods_per_node": 64}},
+			},
+		},
+		"block of other provider": {
+			blocks: map[string]any{
+				FieldNodeConfigurationAKS: []any{map[string]any{"max_pods_per_node": 64}},
+			},
+			expectedErr: `"aks" block can't be used with gke cluster "` + clusterID + `", use "gke" block instead`,
+		},
+		"several blocks": {
+			blocks: map[string]any{
+				FieldNodeConfigurationAKS: []any{map[string]any{"max_pods_per_node": 64}},
+				FieldNodeConfigurationGKE: []any{map[string]any{"max_pods_per_node": 64}},
+			},
+			expectedErr: "conflicts with",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+			mockClient.EXPECT().
+				ExternalClusterAPIGetCluster(gomock.Any(), clusterID).
+				DoAndReturn(func(_ context.Context, _ string, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+					return testJSONResponse(http.StatusOK, `{"id": "`+clusterID+`", "providerType": "gke"}`), nil
+				}).
+				AnyTimes()
+
+			config := map[string]any{
+				FieldClusterID:                clusterID,
+				FieldNodeConfigurationName:    "test",
+				FieldNodeConfigurationSubnets: []any{"subnet-1"},
+			}
+			for k, v := range tt.blocks {
+				config[k] = v
+			}
+
+			resource := resourceNodeConfiguration()
+			raw := terraform.NewResourceConfigRaw(config)
+			if diags := resource.Validate(raw); diags.HasError() {
+				r.NotEmpty(tt.expectedErr)
+				r.Contains(diags[0].Detail, tt.expectedErr)
+				return
+			}
+			_, err := resource.Diff(context.Background(), nil, raw, provider)
+			if tt.expectedErr == "" {
+				r.NoError(err)
+				return
+			}
+			r.Error(err)
+			r.Contains(err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestNodeConfigurationResourceStateUpgradeV1(t *testing.T) {
+	r := require.New(t)
+
+	state := map[string]any{
+		FieldNodeConfigurationSubnets: []any{"subnet-1", "subnet-2", "subnet-1"},
+		FieldNodeConfigurationEKS:     []any{map[string]any{"security_groups": []any{"sg-1", "sg-1"}}},
+	}
+
+	upgraded, err := nodeConfigurationStateUpgradeV1(context.Background(), state, nil)
+	r.NoError(err)
+	r.Equal(map[string]any{
+		FieldNodeConfigurationSubnets: []any{"subnet-1", "subnet-2"},
+		FieldNodeConfigurationEKS:     []any{map[string]any{"security_groups": []any{"sg-1"}}},
+	}, upgraded)
+}
+
 func TestAccResourceNodeConfiguration_basic(t *testing.T) {
 	rName := fmt.Sprintf("%v-node-config-%v", ResourcePrefix, acctest.RandString(8))
 	resourceName := "castai_node_configuration.test"
@@ -82,6 +606,130 @@ func TestAccResourceNodeConfiguration_basic(t *testing.T) {
 	})
 }
 
+func TestAccResourceNodeConfiguration_gke(t *testing.T) {
+	rName := fmt.Sprintf("%v-gke-%v", ResourcePrefix, acctest.RandString(8))
+	resourceName := "castai_node_configuration.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccGKEPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckNodeTemplateDestroy,
+			testAccCheckNodeConfigurationDestroy,
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccGKENodeConfigurationConfig(rName, "core-tf-acc", "us-central1-c"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "disk_cpu_ratio", "25"),
+					resource.TestCheckResourceAttr(resourceName, "subnets.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "gke.0.max_pods_per_node", "31"),
+					resource.TestCheckResourceAttr(resourceName, "gke.0.network_tags.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "eks.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "aks.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "kops.#", "0"),
+					resource.TestCheckResourceAttr("castai_node_template.test", "name", rName),
+					resource.TestCheckResourceAttrPair("castai_node_template.test", "configuration_id", resourceName, "id"),
+				),
+			},
+		},
+		ExternalProviders: testAccGoogleProviders,
+	})
+}
+
+func TestAccResourceNodeConfiguration_aks(t *testing.T) {
+	rName := fmt.Sprintf("%v-aks-%v", ResourcePrefix, acctest.RandString(8))
+	resourceName := "castai_node_configuration.test"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccAKSPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckNodeTemplateDestroy,
+			testAccCheckNodeConfigurationDestroy,
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAKSNodeConfigurationConfig(rName, "core-tf-acc", "core-tf-acc", "core-tf-acc-ng"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "disk_cpu_ratio", "25"),
+					resource.TestCheckResourceAttr(resourceName, "subnets.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "aks.0.max_pods_per_node", "40"),
+					resource.TestCheckResourceAttr(resourceName, "eks.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "gke.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "kops.#", "0"),
+					resource.TestCheckResourceAttr("castai_node_template.test", "name", rName),
+					resource.TestCheckResourceAttrPair("castai_node_template.test", "configuration_id", resourceName, "id"),
+				),
+			},
+		},
+		ExternalProviders: testAccAzureProviders,
+	})
+}
+
+func testAccGKENodeConfigurationConfig(rName, clusterName, location string) string {
+	return ConfigCompose(testAccGKEClusterConfig(rName, clusterName, location), fmt.Sprintf(`
+data "google_compute_subnetwork" "test" {
+  name   = data.google_container_cluster.test.subnetwork
+  region = join("-", slice(split("-", %[2]q), 0, 2))
+}
+
+resource "castai_node_configuration" "test" {
+  name           = %[1]q
+  cluster_id     = castai_gke_cluster.test.id
+  disk_cpu_ratio = 25
+  subnets        = [data.google_compute_subnetwork.test.id]
+  gke {
+    max_pods_per_node = 31
+    network_tags      = ["tf-acc", "castai"]
+  }
+}
+
+resource "castai_node_template" "test" {
+  cluster_id       = castai_gke_cluster.test.id
+  name             = %[1]q
+  configuration_id = castai_node_configuration.test.id
+  constraints {
+    spot    = true
+    min_cpu = 2
+    max_cpu = 8
+  }
+}
+`, rName, location))
+}
+
+func testAccAKSNodeConfigurationConfig(rName, clusterName, resourceGroupName, nodeResourceGroupName string) string {
+	return ConfigCompose(testAccAKSClusterConfig(rName, clusterName, resourceGroupName, nodeResourceGroupName), fmt.Sprintf(`
+data "azurerm_kubernetes_cluster" "test" {
+  name                = %[2]q
+  resource_group_name = %[3]q
+}
+
+resource "castai_node_configuration" "test" {
+  name           = %[1]q
+  cluster_id     = castai_aks_cluster.test.id
+  disk_cpu_ratio = 25
+  subnets        = [data.azurerm_kubernetes_cluster.test.agent_pool_profile[0].vnet_subnet_id]
+  aks {
+    max_pods_per_node = 40
+  }
+}
+
+resource "castai_node_template" "test" {
+  cluster_id       = castai_aks_cluster.test.id
+  name             = %[1]q
+  configuration_id = castai_node_configuration.test.id
+  constraints {
+    spot    = true
+    min_cpu = 2
+    max_cpu = 8
+  }
+}
+`, rName, clusterName, resourceGroupName))
+}
+
 func testAccNodeConfigurationConfig(rName, clusterName string) string {
 	return ConfigCompose(testAccEKSClusterConfig(rName, clusterName), fmt.Sprintf(`
 variable "init_script" {
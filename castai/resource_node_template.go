@@ -2,15 +2,21 @@ package castai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/castai/terraform-provider-castai/castai/sdk"
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/samber/lo"
 	"log"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +31,11 @@ const (
 	FieldNodeTemplateCustomTaints              = "custom_taints"
 	FieldNodeTemplateCustomInstancesEnabled    = "custom_instances_enabled"
 	FieldNodeTemplateConstraints               = "constraints"
+	FieldNodeTemplateVersion                   = "version"
+	FieldNodeTemplateCloneFrom                 = "clone_from"
+	FieldNodeTemplateMatchedInstanceTypes      = "matched_instance_types"
+	FieldNodeTemplateStats                     = "stats"
+	FieldNodeTemplateForceDelete               = "force_delete"
 )
 
 const (
@@ -33,9 +44,7 @@ const (
 )
 
 func resourceNodeTemplate() *schema.Resource {
-	supportedArchitectures := []string{ArchAMD64, ArchARM64}
-
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceNodeTemplateCreate,
 		ReadContext:   resourceNodeTemplateRead,
 		DeleteContext: resourceNodeTemplateDelete,
@@ -53,18 +62,27 @@ func resourceNodeTemplate() *schema.Resource {
 		},
 
 		Schema: map[string]*schema.Schema{
-			FieldClusterId: {
+			FieldClusterID: {
 				Type:             schema.TypeString,
 				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{FieldClusterID, FieldClusterName},
 				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
 				Description:      "CAST AI cluster id.",
 			},
+			FieldClusterName: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "CAST AI cluster name. Can be used instead of `cluster_id`, the id is resolved when the node template is created.",
+			},
 			FieldNodeTemplateName: {
 				Type:             schema.TypeString,
 				Required:         true,
 				ForceNew:         true,
 				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
-				Description:      "Name of the node template.",
+				Description:      "Name of the node template. Templates can't be renamed, changing the name replaces the template.",
 			},
 			FieldNodeTemplateConfigurationId: {
 				Type:             schema.TypeString,
@@ -77,148 +95,7 @@ func resourceNodeTemplate() *schema.Resource {
 				Optional:    true,
 				Description: "Marks whether the templated nodes will have a taint.",
 			},
-			FieldNodeTemplateConstraints: {
-				Type:     schema.TypeList,
-				MaxItems: 1,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"spot": {
-							Type:        schema.TypeBool,
-							Default:     false,
-							Optional:    true,
-							Description: "Spot instance constraint - true only spot, false only on-demand.",
-						},
-						"use_spot_fallbacks": {
-							Type:        schema.TypeBool,
-							Default:     false,
-							Optional:    true,
-							Description: "Spot instance fallback constraint - when true, on-demand instances will be created, when spots are unavailable.",
-						},
-						"fallback_restore_rate_seconds": {
-							Type:        schema.TypeInt,
-							Default:     0,
-							Optional:    true,
-							Description: "Fallback restore rate in seconds: defines how much time should pass before spot fallback should be attempted to be restored to real spot.",
-						},
-						"min_cpu": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Min CPU cores per node.",
-						},
-						"max_cpu": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Max CPU cores per node.",
-						},
-						"min_memory": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Min Memory (Mib) per node.",
-						},
-						"max_memory": {
-							Type:        schema.TypeInt,
-							Optional:    true,
-							Description: "Max Memory (Mib) per node.",
-						},
-						"storage_optimized": {
-							Type:        schema.TypeBool,
-							Optional:    true,
-							Default:     false,
-							Description: "Storage optimized instance constraint - will only pick storage optimized nodes if true",
-						},
-						"compute_optimized": {
-							Type:        schema.TypeBool,
-							Optional:    true,
-							Default:     false,
-							Description: "Compute optimized instance constraint - will only pick compute optimized nodes if true.",
-						},
-						"instance_families": {
-							Type:     schema.TypeList,
-							MaxItems: 1,
-							Optional: true,
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"include": {
-										Type:     schema.TypeList,
-										Optional: true,
-										Elem: &schema.Schema{
-											Type: schema.TypeString,
-										},
-										Description: "Instance families to exclude when filtering (includes all other families).",
-									},
-									"exclude": {
-										Type:     schema.TypeList,
-										Optional: true,
-										Elem: &schema.Schema{
-											Type: schema.TypeString,
-										},
-										Description: "Instance families to include when filtering (excludes all other families).",
-									},
-								},
-							},
-						},
-						"gpu": {
-							Type:     schema.TypeList,
-							MaxItems: 1,
-							Optional: true,
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"manufacturers": {
-										Type:     schema.TypeList,
-										Optional: true,
-										Elem: &schema.Schema{
-											Type: schema.TypeString,
-										},
-										Description: "Manufacturers of the gpus to select - NVIDIA, AMD.",
-									},
-									"include_names": {
-										Type:     schema.TypeList,
-										Optional: true,
-										Elem: &schema.Schema{
-											Type: schema.TypeString,
-										},
-										Description: "Instance families to include when filtering (excludes all other families).",
-									},
-									"exclude_names": {
-										Type:     schema.TypeList,
-										Optional: true,
-										Elem: &schema.Schema{
-											Type: schema.TypeString,
-										},
-										Description: "Names of the GPUs to exclude.",
-									},
-									"min_count": {
-										Type:        schema.TypeInt,
-										Optional:    true,
-										Description: "Min GPU count for the instance type to have.",
-									},
-									"max_count": {
-										Type:        schema.TypeInt,
-										Optional:    true,
-										Description: "Max GPU count for the instance type to have.",
-									},
-								},
-							},
-						},
-						"architectures": {
-							Type:     schema.TypeList,
-							MaxItems: 2,
-							MinItems: 1,
-							Optional: true,
-							Computed: true,
-							Elem: &schema.Schema{
-								Type:             schema.TypeString,
-								ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(supportedArchitectures, false)),
-							},
-							DefaultFunc: func() (interface{}, error) {
-								return []string{ArchAMD64}, nil
-							},
-							Description: fmt.Sprintf("List of acceptable instance CPU architectures, the default is %s. Allowed values: %s.", ArchAMD64, strings.Join(supportedArchitectures, ", ")),
-						},
-					},
-				},
-			},
+			FieldNodeTemplateConstraints: nodeTemplateConstraintsSchema(),
 			FieldNodeTemplateCustomLabel: {
 				Type:     schema.TypeList,
 				MaxItems: 1,
@@ -239,8 +116,11 @@ func resourceNodeTemplate() *schema.Resource {
 						},
 					},
 				},
-				Description: "Custom label key/value to be added to nodes created from this template.",
-				Deprecated:  "Remove the use of `custom_label` field. The custom labels should be set through the `custom_labels` field.",
+				Description: "Custom label key/value to be added to nodes created from this template. " +
+					"The label is added to `custom_labels`, taking precedence over a label with the same key.",
+				Deprecated: "Remove the use of `custom_label` field. The custom labels should be set through the `custom_labels` field. " +
+					"Labels of existing templates are moved to `custom_labels` in state automatically, so only the configuration has to be changed. " +
+					"The field will be removed in the next major version.",
 			},
 			FieldNodeTemplateCustomLabels: {
 				Type:     schema.TypeMap,
@@ -248,40 +128,9 @@ func resourceNodeTemplate() *schema.Resource {
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Description: "Custom labels to be added to nodes created from this template. " +
-					"If the field `custom_label` is present, the value of `custom_labels` will be ignored.",
-			},
-			FieldNodeTemplateCustomTaints: {
-				Type:     schema.TypeList,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"key": {
-							Required:         true,
-							Type:             schema.TypeString,
-							ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
-							Description:      "Key of a taint to be added to nodes created from this template.",
-						},
-						"value": {
-							Required:         true,
-							Type:             schema.TypeString,
-							ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
-							Description:      "Value of a taint to be added to nodes created from this template.",
-						},
-						"effect": {
-							Optional: true,
-							Type:     schema.TypeString,
-							ValidateDiagFunc: validation.ToDiagFunc(
-								validation.StringMatch(regexp.MustCompile("^NoSchedule$"), "effect must be NoSchedule"),
-							),
-							Description: "Effect of a taint to be added to nodes created from this template. The effect must always be NoSchedule.",
-						},
-					},
-				},
-				Description: "Custom taints to be added to the nodes created from this template. " +
-					"`shouldTaint` has to be `true` in order to create/update the node template with custom taints. " +
-					"If `shouldTaint` is `true`, but no custom taints are provided, the nodes will be tainted with the default node template taint.",
+				Description: "Custom labels to be added to nodes created from this template.",
 			},
+			FieldNodeTemplateCustomTaints: nodeTemplateCustomTaintsSchema(),
 			FieldNodeTemplateRebalancingConfigMinNodes: {
 				Type:             schema.TypeInt,
 				Optional:         true,
@@ -296,67 +145,718 @@ func resourceNodeTemplate() *schema.Resource {
 				Description: "Marks whether custom instances should be used when deciding which parts of inventory are available. " +
 					"Custom instances are only supported in GCP.",
 			},
+			FieldNodeTemplateCloneFrom: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description: "Name of an existing node template in the same cluster to copy settings from when creating this template. " +
+					"Settings which are not set in the configuration are copied from that template once, on create, and are not managed afterwards. " +
+					"Settings set in the configuration override the copied ones.",
+			},
+			FieldNodeTemplateForceDelete: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Delete the template even when nodes are still running from it, leaving them without a template. " +
+					"Deletion of templates with active nodes fails otherwise. It has to be applied before the template " +
+					"is deleted to take effect.",
+			},
+			FieldNodeTemplateVersion: {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "Version of the node template. It is changed by CAST AI on every update of the template. " +
+					"Before an update it is compared with the current version, so modifications made outside of Terraform " +
+					"since the last refresh fail the apply. The check is done by the provider and not enforced by the API, " +
+					"so changes made between the check and the update are still overwritten.",
+			},
+			FieldNodeTemplateMatchedInstanceTypes: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Names of instance types matching the template constraints in the cluster's region. " +
+					"Resolved during plan when constraints change, so they can be reviewed before applying, and on apply. " +
+					"They are not refreshed, so changes of available instance types show up on the next change of constraints. " +
+					"An empty list means that no nodes can be provisioned from the template.",
+			},
+			FieldNodeTemplateStats: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"nodes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of nodes currently provisioned from the template.",
+						},
+						"on_demand_nodes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of on-demand nodes.",
+						},
+						"spot_nodes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of spot nodes.",
+						},
+						"fallback_nodes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of on-demand nodes provisioned as fallback of spot nodes.",
+						},
+					},
+				},
+				Description: "Nodes running from the template at the last refresh, so plans reveal whether changing or " +
+					"deleting the template affects running capacity.",
+			},
+		},
+		CustomizeDiff: customdiff.All(
+			clusterNameForceNew,
+			nodeTemplateVersionDiff,
+			nodeTemplateMemoryDiff,
+			nodeTemplateConstraintRangesDiff,
+			nodeTemplateInstanceFamiliesDiff,
+			nodeTemplateMatchedInstanceTypesDiff,
+		),
+		SchemaVersion: 3,
+	}
+	// Attributes were only added since version 0, so its state is decoded with the current schema.
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: nodeTemplateStateUpgradeV0,
+		},
+		{
+			Version: 1,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: nodeTemplateStateUpgradeV1,
+		},
+		{
+			Version: 2,
+			Type:    r.CoreConfigSchema().ImpliedType(),
+			Upgrade: nodeTemplateStateUpgradeV2,
+		},
+	}
+
+	return r
+}
+
+// nodeTemplateConstraintsSchema returns schema of template constraints, it's shared by single and bulk node template
+// resources.
+func nodeTemplateConstraintsSchema() *schema.Schema {
+	supportedArchitectures := []string{ArchAMD64, ArchARM64}
+
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		MaxItems: 1,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"spot": {
+					Type:        schema.TypeBool,
+					Default:     false,
+					Optional:    true,
+					Description: "Spot instance constraint - true only spot, false only on-demand.",
+				},
+				"use_spot_fallbacks": {
+					Type:        schema.TypeBool,
+					Default:     false,
+					Optional:    true,
+					Description: "Spot instance fallback constraint - when true, on-demand instances will be created, when spots are unavailable.",
+				},
+				"fallback_restore_rate_seconds": {
+					Type:        schema.TypeInt,
+					Default:     0,
+					Optional:    true,
+					Description: "Fallback restore rate in seconds: defines how much time should pass before spot fallback should be attempted to be restored to real spot.",
+				},
+				"min_cpu": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Description: "Min CPU cores per node.",
+				},
+				"max_cpu": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Description: "Max CPU cores per node.",
+				},
+				"min_memory": {
+					Type:             schema.TypeInt,
+					Optional:         true,
+					DiffSuppressFunc: suppressNormalizedMemoryDiff,
+					Description:      fmt.Sprintf("Min Memory (Mib) per node. Must be a multiple of %d unless `normalize_memory` is enabled.", memoryGranularityMiB),
+				},
+				"max_memory": {
+					Type:             schema.TypeInt,
+					Optional:         true,
+					DiffSuppressFunc: suppressNormalizedMemoryDiff,
+					Description:      fmt.Sprintf("Max Memory (Mib) per node. Must be a multiple of %d unless `normalize_memory` is enabled.", memoryGranularityMiB),
+				},
+				"normalize_memory": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+					Description: fmt.Sprintf("When true, `min_memory` is rounded up and `max_memory` is rounded down to a multiple of %d MiB "+
+						"accepted by the API, instead of failing the plan.", memoryGranularityMiB),
+				},
+				"skip_instance_families_validation": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+					Description: "When true, `instance_families` aren't validated against instance families available for the cluster " +
+						"during plan.",
+				},
+				"storage_optimized": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Storage optimized instance constraint - will only pick storage optimized nodes if true",
+				},
+				"compute_optimized": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Compute optimized instance constraint - will only pick compute optimized nodes if true.",
+				},
+				"instance_families": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"include": {
+								Type:     schema.TypeSet,
+								Optional: true,
+								Elem: &schema.Schema{
+									Type: schema.TypeString,
+								},
+								Set:         hashStringIgnoreCase,
+								Description: "Instance families to exclude when filtering (includes all other families).",
+							},
+							"exclude": {
+								Type:     schema.TypeSet,
+								Optional: true,
+								Elem: &schema.Schema{
+									Type: schema.TypeString,
+								},
+								Set:         hashStringIgnoreCase,
+								Description: "Instance families to include when filtering (excludes all other families).",
+							},
+						},
+					},
+				},
+				"gpu": {
+					Type:     schema.TypeList,
+					MaxItems: 1,
+					Optional: true,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"manufacturers": {
+								Type:     schema.TypeSet,
+								Optional: true,
+								Elem: &schema.Schema{
+									Type: schema.TypeString,
+								},
+								Description: "Manufacturers of the gpus to select - NVIDIA, AMD.",
+							},
+							"include_names": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem: &schema.Schema{
+									Type: schema.TypeString,
+								},
+								DiffSuppressFunc: suppressEquivalentNamesDiff,
+								Description:      "Instance families to include when filtering (excludes all other families).",
+							},
+							"exclude_names": {
+								Type:     schema.TypeList,
+								Optional: true,
+								Elem: &schema.Schema{
+									Type: schema.TypeString,
+								},
+								DiffSuppressFunc: suppressEquivalentNamesDiff,
+								Description:      "Names of the GPUs to exclude.",
+							},
+							"min_count": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Description: "Min GPU count for the instance type to have.",
+							},
+							"max_count": {
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Description: "Max GPU count for the instance type to have.",
+							},
+						},
+					},
+				},
+				"architectures": {
+					Type:     schema.TypeSet,
+					MaxItems: 2,
+					MinItems: 1,
+					Optional: true,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type:             schema.TypeString,
+						ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(supportedArchitectures, false)),
+					},
+					DefaultFunc: func() (interface{}, error) {
+						return []string{ArchAMD64}, nil
+					},
+					Description: fmt.Sprintf("List of acceptable instance CPU architectures, the default is %s. Allowed values: %s.", ArchAMD64, strings.Join(supportedArchitectures, ", ")),
+				},
+			},
+		},
+	}
+}
+
+// nodeTemplateCustomTaintsSchema returns schema of template custom taints, it's shared by single and bulk node template
+// resources.
+func nodeTemplateCustomTaintsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key": {
+					Required:         true,
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+					Description:      "Key of a taint to be added to nodes created from this template.",
+				},
+				"value": {
+					Required:         true,
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+					Description:      "Value of a taint to be added to nodes created from this template.",
+				},
+				"effect": {
+					Optional: true,
+					Type:     schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(
+						validation.StringMatch(regexp.MustCompile("^NoSchedule$"), "effect must be NoSchedule"),
+					),
+					Description: "Effect of a taint to be added to nodes created from this template. The effect must always be NoSchedule.",
+				},
+			},
+		},
+		Description: "Custom taints to be added to the nodes created from this template. " +
+			"`shouldTaint` has to be `true` in order to create/update the node template with custom taints. " +
+			"If `shouldTaint` is `true`, but no custom taints are provided, the nodes will be tainted with the default node template taint.",
+	}
+}
+
+// nodeTemplateStateUpgradeV0 sets constraint flags, which aren't returned by CAST AI, to their defaults, so states
+// written before the flags were added don't show changes.
+func nodeTemplateStateUpgradeV0(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	constraints, _ := rawState[FieldNodeTemplateConstraints].([]any)
+	for _, v := range constraints {
+		c, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"normalize_memory", "skip_instance_families_validation"} {
+			if _, ok := c[key].(bool); !ok {
+				c[key] = false
+			}
+		}
+	}
+
+	return rawState, nil
+}
+
+// nodeTemplateStateUpgradeV1 moves the label of the deprecated custom_label to custom_labels, so templates which are
+// migrated to custom_labels in configuration don't show changes.
+func nodeTemplateStateUpgradeV1(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	customLabel, _ := rawState[FieldNodeTemplateCustomLabel].([]any)
+	if len(customLabel) == 0 {
+		return rawState, nil
+	}
+
+	labels, _ := rawState[FieldNodeTemplateCustomLabels].(map[string]any)
+	if labels == nil {
+		labels = map[string]any{}
+	}
+	if l, ok := customLabel[0].(map[string]any); ok {
+		if key, _ := l["key"].(string); key != "" {
+			labels[key] = l["value"]
+		}
+	}
+	rawState[FieldNodeTemplateCustomLabels] = labels
+	rawState[FieldNodeTemplateCustomLabel] = []any{}
+
+	return rawState, nil
+}
+
+// nodeTemplateStateUpgradeV2 drops duplicate values of lists which were converted to sets. Instance families are
+// compared ignoring case, the same way as their set is hashed.
+func nodeTemplateStateUpgradeV2(_ context.Context, rawState map[string]any, _ any) (map[string]any, error) {
+	for _, c := range stateBlocks(rawState, FieldNodeTemplateConstraints) {
+		c["architectures"] = uniqueStateValues(c["architectures"], false)
+		for _, families := range stateBlocks(c, "instance_families") {
+			families["include"] = uniqueStateValues(families["include"], true)
+			families["exclude"] = uniqueStateValues(families["exclude"], true)
+		}
+		for _, gpu := range stateBlocks(c, "gpu") {
+			gpu["manufacturers"] = uniqueStateValues(gpu["manufacturers"], false)
+		}
+	}
+
+	return rawState, nil
+}
+
+// nodeTemplateVersionDiff marks version as unknown whenever the template is going to be updated, since CAST AI bumps it.
+func nodeTemplateVersionDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	if d.Id() == "" || !hasPlannedChanges(d, nodeTemplateUpdatableFields...) {
+		return nil
+	}
+
+	return d.SetNewComputed(FieldNodeTemplateVersion)
+}
+
+// nodeTemplateMatchedInstanceTypesDiff resolves instance types matching planned constraints. Matched instance types
+// are left unknown until apply when they can't be resolved during plan.
+func nodeTemplateMatchedInstanceTypesDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	if d.Id() != "" && !hasPlannedChanges(d, FieldNodeTemplateConstraints, FieldNodeTemplateCustomInstancesEnabled) {
+		return nil
+	}
+
+	clusterID, ok := d.Get(FieldClusterID).(string)
+	if !ok || clusterID == "" || !d.NewValueKnown(FieldClusterID) || !d.NewValueKnown(FieldNodeTemplateConstraints) ||
+		(d.Id() == "" && d.Get(FieldNodeTemplateCloneFrom).(string) != "") || isOffline(meta) {
+		return d.SetNewComputed(FieldNodeTemplateMatchedInstanceTypes)
+	}
+
+	filter := sdk.NodetemplatesV1NodeTemplate{
+		CustomInstancesEnabled: lo.ToPtr(d.Get(FieldNodeTemplateCustomInstancesEnabled).(bool)),
+	}
+	if v, ok := d.Get(FieldNodeTemplateConstraints).([]any); ok && len(v) > 0 && v[0] != nil {
+		filter.Constraints = toTemplateConstraints(withConfiguredMinValues(v[0].(map[string]any), nodeTemplateConstraintsConfig(d)))
+	}
+
+	names, err := matchInstanceTypes(ctx, meta, clusterID, filter)
+	if err != nil {
+		log.Printf("[WARN] Matched instance types will be known after apply: %v", err)
+		return d.SetNewComputed(FieldNodeTemplateMatchedInstanceTypes)
+	}
+	if len(names) == 0 {
+		// SDKv2 can't return warnings from plan, empty matched_instance_types in the plan is the visible signal.
+		log.Printf("[WARN] No instance types match constraints of node template %q, no nodes will be provisioned from it",
+			d.Get(FieldNodeTemplateName).(string))
+	}
+
+	return d.SetNew(FieldNodeTemplateMatchedInstanceTypes, names)
+}
+
+// matchInstanceTypes returns sorted names of instance types which match constraints of the template.
+func matchInstanceTypes(ctx context.Context, meta any, clusterID string, template sdk.NodetemplatesV1NodeTemplate) ([]string, error) {
+	client := meta.(*ProviderConfig).api
+
+	resp, err := client.NodeTemplatesAPIFilterInstanceTypesWithResponse(ctx, clusterID, sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody{
+		Constraints:            template.Constraints,
+		CustomInstancesEnabled: template.CustomInstancesEnabled,
+	})
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return nil, fmt.Errorf("filtering instance types: %w", checkErr)
+	}
+
+	names := lo.Map(lo.FromPtr(resp.JSON200.AvailableInstanceTypes), func(t sdk.NodetemplatesV1AvailableInstanceType, _ int) string {
+		return lo.FromPtr(t.Name)
+	})
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// setMatchedInstanceTypes resolves instance types matching the template after apply. They aren't resolved on refresh,
+// which would cost an API call per template, so a failure is only logged and doesn't fail the apply.
+func setMatchedInstanceTypes(ctx context.Context, d *schema.ResourceData, meta any, template *sdk.NodetemplatesV1NodeTemplate) diag.Diagnostics {
+	matched, err := matchInstanceTypes(ctx, meta, getClusterId(d), *template)
+	if err != nil {
+		log.Printf("[WARN] Matched instance types of node template %q can't be resolved: %v", d.Id(), err)
+		return nil
+	}
+	if err := d.Set(FieldNodeTemplateMatchedInstanceTypes, matched); err != nil {
+		return diag.FromErr(fmt.Errorf("setting matched instance types: %w", err))
+	}
+
+	return nil
+}
+
+// memoryGranularityMiB is the granularity of memory constraints accepted by the API.
+const memoryGranularityMiB = 1024
+
+// nodeTemplateMemoryDiff rejects memory constraints which would be rejected by the API, unless they are normalized.
+func nodeTemplateMemoryDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	return validateMemoryConstraints(d.Get(FieldNodeTemplateConstraints), FieldNodeTemplateConstraints)
+}
+
+// validateMemoryConstraints checks memory of constraints list at the path, unless it's normalized.
+func validateMemoryConstraints(v any, path string) error {
+	constraints, ok := v.([]any)
+	if !ok || len(constraints) == 0 || constraints[0] == nil {
+		return nil
+	}
+	c := constraints[0].(map[string]any)
+	if normalize, _ := c["normalize_memory"].(bool); normalize {
+		return nil
+	}
+
+	for _, key := range []string{"min_memory", "max_memory"} {
+		if v, _ := c[key].(int); v%memoryGranularityMiB != 0 {
+			return fmt.Errorf("%[1]s.0.%[2]s must be a multiple of %[3]d MiB, got %[4]d; set %[1]s.0.normalize_memory to round it automatically",
+				path, key, memoryGranularityMiB, v)
+		}
+	}
+
+	return nil
+}
+
+// suppressNormalizedMemoryDiff suppresses the diff between configured memory constraint and its normalized value
+// stored by the API. The flag is looked up next to the memory constraint, so templates of bulk resource are supported.
+func suppressNormalizedMemoryDiff(k, old, new string, d *schema.ResourceData) bool {
+	if normalize, _ := d.Get(k[:strings.LastIndex(k, ".")] + ".normalize_memory").(bool); !normalize {
+		return false
+	}
+	o, err := strconv.Atoi(old)
+	if err != nil {
+		return false
+	}
+	n, err := strconv.Atoi(new)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasSuffix(k, "min_memory") {
+		return o == roundUpMemory(n)
+	}
+	return o == roundDownMemory(n)
+}
+
+// hashStringIgnoreCase hashes names of sets which CAST AI normalizes, so names which only differ in case are the same.
+func hashStringIgnoreCase(v any) int {
+	return schema.HashString(strings.ToLower(v.(string)))
+}
+
+// suppressEquivalentNamesDiff suppresses changes of name lists which only differ in case or order of the names, since
+// CAST AI normalizes GPU names.
+func suppressEquivalentNamesDiff(k, _, _ string, d *schema.ResourceData) bool {
+	// Suppression is called for the list length and each of its items, the whole list is compared in all cases.
+	o, n := d.GetChange(k[:strings.LastIndex(k, ".")])
+	normalize := func(names []any) []string {
+		out := lo.Map(names, func(name any, _ int) string {
+			v, _ := name.(string)
+			return strings.ToLower(v)
+		})
+		sort.Strings(out)
+		return out
+	}
+
+	return reflect.DeepEqual(normalize(o.([]any)), normalize(n.([]any)))
+}
+
+func roundUpMemory(v int) int {
+	return (v + memoryGranularityMiB - 1) / memoryGranularityMiB * memoryGranularityMiB
+}
+
+// roundDownMemory rounds the value down, but never to 0 which would remove the constraint.
+func roundDownMemory(v int) int {
+	return lo.Max([]int{v / memoryGranularityMiB * memoryGranularityMiB, memoryGranularityMiB})
+}
+
+// nodeTemplateInstanceFamiliesDiff does a best-effort plan time check that included and excluded instance families are
+// available for the cluster. The check is skipped when the cluster is not known yet or its instance types can't be listed.
+func nodeTemplateInstanceFamiliesDiff(ctx context.Context, d *schema.ResourceDiff, meta any) error {
+	if (d.Id() != "" && !hasPlannedChanges(d, FieldNodeTemplateConstraints)) || isOffline(meta) {
+		return nil
+	}
+	constraints, ok := d.Get(FieldNodeTemplateConstraints).([]any)
+	if !ok || len(constraints) == 0 || constraints[0] == nil {
+		return nil
+	}
+	c := constraints[0].(map[string]any)
+	if skip, _ := c["skip_instance_families_validation"].(bool); skip {
+		return nil
+	}
+	v, ok := c["instance_families"].([]any)
+	if !ok || len(v) == 0 || v[0] == nil {
+		return nil
+	}
+	families := toTemplateConstraintsInstanceFamilies(v[0].(map[string]any))
+	configured := lo.Flatten([][]string{lo.FromPtr(families.Include), lo.FromPtr(families.Exclude)})
+	if len(configured) == 0 {
+		return nil
+	}
+
+	clusterID, ok := d.Get(FieldClusterID).(string)
+	if !ok || clusterID == "" || !d.NewValueKnown(FieldClusterID) {
+		return nil
+	}
+
+	client := meta.(*ProviderConfig).api
+	resp, err := client.NodeTemplatesAPIFilterInstanceTypesWithResponse(ctx, clusterID, sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody{
+		Constraints: &sdk.NodetemplatesV1TemplateConstraints{
+			Architectures: &[]string{ArchAMD64, ArchARM64},
 		},
+	})
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		log.Printf("[WARN] Skipping instance families validation, instance types of cluster %q can't be listed: %v", clusterID, checkErr)
+		return nil
+	}
+
+	available := map[string]struct{}{}
+	for _, t := range lo.FromPtr(resp.JSON200.AvailableInstanceTypes) {
+		available[strings.ToLower(lo.FromPtr(t.Family))] = struct{}{}
+	}
+	if len(available) == 0 {
+		return nil
+	}
+
+	unknown := lo.Uniq(lo.Filter(configured, func(family string, _ int) bool {
+		_, ok := available[strings.ToLower(family)]
+		return !ok
+	}))
+	if len(unknown) > 0 {
+		return fmt.Errorf("instance families %s are not available for cluster %q; set constraints.0.skip_instance_families_validation to skip the check",
+			strings.Join(unknown, ", "), clusterID)
+	}
+
+	return nil
+}
+
+// nodeTemplateConstraintRangesDiff rejects constraints with max value lower than min value. Zero max values are not
+// sent to the API, so they don't limit the range.
+func nodeTemplateConstraintRangesDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	constraints, ok := d.Get(FieldNodeTemplateConstraints).([]any)
+	if !ok || len(constraints) == 0 || constraints[0] == nil {
+		return nil
+	}
+	c := constraints[0].(map[string]any)
+
+	if err := validateConstraintRange(c, "constraints.0", "min_cpu", "max_cpu"); err != nil {
+		return err
+	}
+	if normalize, _ := c["normalize_memory"].(bool); normalize {
+		minMemory, _ := c["min_memory"].(int)
+		maxMemory, _ := c["max_memory"].(int)
+		if minMemory != 0 && maxMemory != 0 && roundDownMemory(maxMemory) < roundUpMemory(minMemory) {
+			return fmt.Errorf("constraints.0.max_memory (%d) must be greater than or equal to constraints.0.min_memory (%d) "+
+				"after normalization to multiples of %d MiB", maxMemory, minMemory, memoryGranularityMiB)
+		}
+	} else if err := validateConstraintRange(c, "constraints.0", "min_memory", "max_memory"); err != nil {
+		return err
+	}
+	if gpu, ok := c["gpu"].([]any); ok && len(gpu) > 0 && gpu[0] != nil {
+		if err := validateConstraintRange(gpu[0].(map[string]any), "constraints.0.gpu.0", "min_count", "max_count"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateConstraintRange(c map[string]any, prefix, minKey, maxKey string) error {
+	minValue, _ := c[minKey].(int)
+	maxValue, _ := c[maxKey].(int)
+	if maxValue == 0 || maxValue >= minValue {
+		return nil
 	}
+
+	return fmt.Errorf("%[1]s.%[2]s (%[3]d) must be greater than or equal to %[1]s.%[4]s (%[5]d)", prefix, maxKey, maxValue, minKey, minValue)
 }
 
 func resourceNodeTemplateRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	_, diags := readNodeTemplate(ctx, d, meta)
+	return diags
+}
+
+// readNodeTemplate sets the state from the node template and returns the template as returned by the API, or nil when
+// it doesn't exist anymore.
+func readNodeTemplate(ctx context.Context, d *schema.ResourceData, meta any) (*sdk.NodetemplatesV1NodeTemplate, diag.Diagnostics) {
 	log.Printf("[INFO] List Node Templates get call start")
 	defer log.Printf("[INFO] List Node Templates get call end")
 
 	clusterID := getClusterId(d)
 	if clusterID == "" {
-		log.Print("[INFO] ClusterId is missing. Will skip operation.")
-		return nil
+		return nil, diag.Errorf("%q of node template %q is not set, import the node template to set it", FieldClusterID, d.Id())
 	}
 
-	nodeTemplate, err := getNodeTemplateByName(ctx, d, meta, clusterID)
+	item, err := findNodeTemplateListItem(ctx, meta, clusterID, d.Id())
+	if removeFromStateIfNotFound(d, "Node template", isNotFoundErr(err)) {
+		return nil, nil
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, diag.FromErr(err)
 	}
-	if !d.IsNewResource() && nodeTemplate == nil {
-		log.Printf("[WARN] Node template (%s) not found, removing from state", d.Id())
-		d.SetId("")
-		return nil
+	found := item.Template
+
+	if err := d.Set(FieldNodeTemplateStats, flattenNodeTemplateStats(item.Stats)); err != nil {
+		return nil, diag.FromErr(fmt.Errorf("setting stats: %w", err))
 	}
+
+	// Templates are shared with the response cache, settings copied from clone_from template are dropped from a copy.
+	// found keeps them for instance type matching.
+	nodeTemplate, err := copyNodeTemplate(found)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	dropClonedSettings(d, nodeTemplate)
 	if err := d.Set(FieldNodeTemplateName, nodeTemplate.Name); err != nil {
-		return diag.FromErr(fmt.Errorf("setting name: %w", err))
+		return nil, diag.FromErr(fmt.Errorf("setting name: %w", err))
 	}
 	if err := d.Set(FieldNodeTemplateConfigurationId, nodeTemplate.ConfigurationId); err != nil {
-		return diag.FromErr(fmt.Errorf("setting configuration id: %w", err))
+		return nil, diag.FromErr(fmt.Errorf("setting configuration id: %w", err))
 	}
 	if err := d.Set(FieldNodeTemplateShouldTaint, nodeTemplate.ShouldTaint); err != nil {
-		return diag.FromErr(fmt.Errorf("setting should taint: %w", err))
+		return nil, diag.FromErr(fmt.Errorf("setting should taint: %w", err))
 	}
 	if nodeTemplate.RebalancingConfig != nil {
 		if err := d.Set(FieldNodeTemplateRebalancingConfigMinNodes, nodeTemplate.RebalancingConfig.MinNodes); err != nil {
-			return diag.FromErr(fmt.Errorf("setting configuration id: %w", err))
+			return nil, diag.FromErr(fmt.Errorf("setting configuration id: %w", err))
 		}
 	}
 	if nodeTemplate.Constraints != nil {
 		constraints, err := flattenConstraints(nodeTemplate.Constraints)
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("flattening constraints: %w", err))
+			return nil, diag.FromErr(fmt.Errorf("flattening constraints: %w", err))
 		}
+		// Normalization is done by the provider, so the setting isn't returned by the API.
+		constraints[0]["normalize_memory"] = d.Get(FieldNodeTemplateConstraints + ".0.normalize_memory").(bool)
+		constraints[0]["skip_instance_families_validation"] = d.Get(FieldNodeTemplateConstraints + ".0.skip_instance_families_validation").(bool)
 
 		if err := d.Set(FieldNodeTemplateConstraints, constraints); err != nil {
-			return diag.FromErr(fmt.Errorf("setting constraints: %w", err))
+			return nil, diag.FromErr(fmt.Errorf("setting constraints: %w", err))
 		}
 	}
-	if err := d.Set(FieldNodeTemplateCustomLabel, flattenCustomLabel(nodeTemplate.CustomLabel)); err != nil {
-		return diag.FromErr(fmt.Errorf("setting custom label: %w", err))
+	labels := withCustomLabel(lo.FromPtr(nodeTemplate.CustomLabels).AdditionalProperties, nodeTemplate.CustomLabel)
+	// Label of the deprecated custom_label is kept in its own attribute while it's configured, so it doesn't show up as
+	// a change of custom_labels.
+	customLabel := configuredCustomLabel(d)
+	if customLabel != nil && customLabel.Key != nil && labels[*customLabel.Key] == lo.FromPtr(customLabel.Value) {
+		delete(labels, *customLabel.Key)
+	} else {
+		customLabel = nil
+	}
+	if err := d.Set(FieldNodeTemplateCustomLabel, flattenCustomLabel(customLabel)); err != nil {
+		return nil, diag.FromErr(fmt.Errorf("setting custom label: %w", err))
 	}
-	if err := d.Set(FieldNodeTemplateCustomLabels, nodeTemplate.CustomLabels.AdditionalProperties); err != nil {
-		return diag.FromErr(fmt.Errorf("setting custom labels: %w", err))
+	if err := d.Set(FieldNodeTemplateCustomLabels, labels); err != nil {
+		return nil, diag.FromErr(fmt.Errorf("setting custom labels: %w", err))
 	}
 	if err := d.Set(FieldNodeTemplateCustomTaints, flattenCustomTaints(nodeTemplate.CustomTaints)); err != nil {
-		return diag.FromErr(fmt.Errorf("setting custom taints: %w", err))
+		return nil, diag.FromErr(fmt.Errorf("setting custom taints: %w", err))
 	}
 	if err := d.Set(FieldNodeTemplateCustomInstancesEnabled, lo.FromPtrOr(nodeTemplate.CustomInstancesEnabled, false)); err != nil {
-		return diag.FromErr(fmt.Errorf("setting custom instances enabled: %w", err))
+		return nil, diag.FromErr(fmt.Errorf("setting custom instances enabled: %w", err))
+	}
+	if err := d.Set(FieldNodeTemplateVersion, lo.FromPtr(nodeTemplate.Version)); err != nil {
+		return nil, diag.FromErr(fmt.Errorf("setting version: %w", err))
 	}
 
-	return nil
+	return found, nil
 }
 
 func flattenConstraints(c *sdk.NodetemplatesV1TemplateConstraints) ([]map[string]any, error) {
@@ -431,7 +931,7 @@ func flattenGpu(gpu *sdk.NodetemplatesV1TemplateConstraintsGPUConstraints) []map
 		out["include_names"] = gpu.IncludeNames
 	}
 	if gpu.Manufacturers != nil {
-		out["manufacturers"] = gpu.Manufacturers
+		out["manufacturers"] = lo.FromPtr(gpu.Manufacturers)
 	}
 	if gpu.MinCount != nil {
 		out["min_count"] = gpu.MinCount
@@ -447,7 +947,23 @@ func resourceNodeTemplateDelete(ctx context.Context, d *schema.ResourceData, met
 	clusterID := d.Get(FieldClusterID).(string)
 	name := d.Get(FieldNodeTemplateName).(string)
 
+	if !d.Get(FieldNodeTemplateForceDelete).(bool) {
+		item, err := findNodeTemplateListItem(ctx, meta, clusterID, name)
+		if err != nil && !isNotFoundErr(err) {
+			return diag.FromErr(err)
+		}
+		if err == nil && item.Stats != nil {
+			onDemand, spot, fallback := lo.FromPtr(item.Stats.CountOnDemand), lo.FromPtr(item.Stats.CountSpot), lo.FromPtr(item.Stats.CountFallback)
+			if nodes := onDemand + spot + fallback; nodes > 0 {
+				return diag.Errorf("node template %q still has %d active nodes (%d on-demand, %d spot, %d fallback), deleting it "+
+					"leaves them without a template. Remove the nodes first or set %q to true to delete it anyway",
+					name, nodes, onDemand, spot, fallback, FieldNodeTemplateForceDelete)
+			}
+		}
+	}
+
 	resp, err := client.NodeTemplatesAPIDeleteNodeTemplateWithResponse(ctx, clusterID, name)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeTemplates, clusterID))
 	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
 		return diag.FromErr(checkErr)
 	}
@@ -455,18 +971,20 @@ func resourceNodeTemplateDelete(ctx context.Context, d *schema.ResourceData, met
 	return nil
 }
 
+var nodeTemplateUpdatableFields = []string{
+	FieldNodeTemplateName,
+	FieldNodeTemplateShouldTaint,
+	FieldNodeTemplateConfigurationId,
+	FieldNodeTemplateRebalancingConfigMinNodes,
+	FieldNodeTemplateCustomLabel,
+	FieldNodeTemplateCustomLabels,
+	FieldNodeTemplateCustomTaints,
+	FieldNodeTemplateCustomInstancesEnabled,
+	FieldNodeTemplateConstraints,
+}
+
 func resourceNodeTemplateUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
-	if !d.HasChanges(
-		FieldNodeTemplateName,
-		FieldNodeTemplateShouldTaint,
-		FieldNodeTemplateConfigurationId,
-		FieldNodeTemplateRebalancingConfigMinNodes,
-		FieldNodeTemplateCustomLabel,
-		FieldNodeTemplateCustomLabels,
-		FieldNodeTemplateCustomTaints,
-		FieldNodeTemplateCustomInstancesEnabled,
-		FieldNodeTemplateConstraints,
-	) {
+	if !d.HasChanges(nodeTemplateUpdatableFields...) {
 		log.Printf("[INFO] Nothing to update in node configuration")
 		return nil
 	}
@@ -475,71 +993,134 @@ func resourceNodeTemplateUpdate(ctx context.Context, d *schema.ResourceData, met
 	clusterID := d.Get(FieldClusterID).(string)
 	name := d.Get(FieldNodeTemplateName).(string)
 
+	if diags := checkNodeTemplateVersion(ctx, d, meta, clusterID); diags != nil {
+		return diags
+	}
+
 	req := sdk.NodeTemplatesAPIUpdateNodeTemplateJSONRequestBody{}
 	if v, ok := d.GetOk(FieldNodeTemplateConfigurationId); ok {
 		req.ConfigurationId = toPtr(v.(string))
 	}
 
-	if v, ok := d.Get(FieldNodeTemplateCustomLabel).([]any); ok && len(v) > 0 {
-		req.CustomLabel = toCustomLabel(v[0].(map[string]any))
+	if labels := toCustomLabels(d); len(labels) > 0 {
+		req.CustomLabels = &sdk.NodetemplatesV1UpdateNodeTemplate_CustomLabels{AdditionalProperties: labels}
 	}
 
-	if req.CustomLabel == nil {
-		if v, ok := d.Get(FieldNodeTemplateCustomLabels).(map[string]any); ok && len(v) > 0 {
-			customLabels := map[string]string{}
-
-			for k, v := range v {
-				customLabels[k] = v.(string)
-			}
-
-			req.CustomLabels = &sdk.NodetemplatesV1UpdateNodeTemplate_CustomLabels{AdditionalProperties: customLabels}
-		}
+	// API keeps the previous value of top level fields which are not sent, so removed fields have to be cleared
+	// explicitly. Constraints are replaced as a whole, with unset values sent as null.
+	if req.CustomLabels == nil && removed(d, FieldNodeTemplateCustomLabel, FieldNodeTemplateCustomLabels) {
+		req.CustomLabels = &sdk.NodetemplatesV1UpdateNodeTemplate_CustomLabels{AdditionalProperties: map[string]string{}}
 	}
 
-	if v, _ := d.GetOk(FieldNodeTemplateShouldTaint); v != nil {
+	if v, _ := d.GetOk(FieldNodeTemplateShouldTaint); v != nil && !inheritedFromClone(d, FieldNodeTemplateShouldTaint) {
 		req.ShouldTaint = toPtr(v.(bool))
 	}
 
-	if v, ok := d.Get(FieldNodeTemplateCustomTaints).([]any); ok && len(v) > 0 {
-		ts := []map[string]any{}
-		for _, val := range v {
-			ts = append(ts, val.(map[string]any))
-		}
+	req.CustomTaints = toTaints(d)
 
-		req.CustomTaints = toCustomTaintsWithoutEffect(ts)
+	if req.CustomTaints == nil && removed(d, FieldNodeTemplateCustomTaints) {
+		req.CustomTaints = &[]sdk.NodetemplatesV1TaintWithoutEffect{}
 	}
 
-	if !(*req.ShouldTaint) && req.CustomTaints != nil && len(*req.CustomTaints) > 0 {
-		return diag.FromErr(fmt.Errorf("shouldTaint must be true for the node template to get updated with custom taints"))
+	if err := validateTaints(req.ShouldTaint, req.CustomTaints); err != nil {
+		return diag.FromErr(err)
 	}
 
-	if v, _ := d.GetOk(FieldNodeTemplateRebalancingConfigMinNodes); v != nil {
+	if v, _ := d.GetOk(FieldNodeTemplateRebalancingConfigMinNodes); v != nil && !inheritedFromClone(d, FieldNodeTemplateRebalancingConfigMinNodes) {
 		req.RebalancingConfig = &sdk.NodetemplatesV1RebalancingConfiguration{
 			MinNodes: toPtr(int32(v.(int))),
 		}
 	}
 
 	if v, ok := d.Get(FieldNodeTemplateConstraints).([]any); ok && len(v) > 0 {
-		req.Constraints = toTemplateConstraints(v[0].(map[string]any))
+		req.Constraints = toTemplateConstraints(withConfiguredMinValues(v[0].(map[string]any), nodeTemplateConstraintsConfig(d)))
 	}
 
-	if v, _ := d.GetOk(FieldNodeTemplateCustomInstancesEnabled); v != nil {
+	if req.Constraints == nil && removed(d, FieldNodeTemplateConstraints) {
+		req.Constraints = &sdk.NodetemplatesV1TemplateConstraints{}
+	}
+
+	if v, _ := d.GetOk(FieldNodeTemplateCustomInstancesEnabled); v != nil && !inheritedFromClone(d, FieldNodeTemplateCustomInstancesEnabled) {
 		req.CustomInstancesEnabled = lo.ToPtr(v.(bool))
 	}
 
 	resp, err := client.NodeTemplatesAPIUpdateNodeTemplateWithResponse(ctx, clusterID, name, req)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeTemplates, clusterID))
 	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
 		return diag.FromErr(checkErr)
 	}
 
-	return resourceNodeTemplateRead(ctx, d, meta)
+	template, diags := readNodeTemplate(ctx, d, meta)
+	if template != nil && !diags.HasError() {
+		diags = append(diags, setMatchedInstanceTypes(ctx, d, meta, template)...)
+	}
+
+	return append(diags, customLabelDeprecationWarning(d)...)
+}
+
+// removed returns true when at least one of the keys had a value before and none of them has it now.
+func removed(d *schema.ResourceData, keys ...string) bool {
+	var hadValue bool
+	for _, key := range keys {
+		if !d.HasChange(key) {
+			continue
+		}
+		old, new := d.GetChange(key)
+		if !isEmptyCollection(new) {
+			return false
+		}
+		hadValue = hadValue || !isEmptyCollection(old)
+	}
+
+	return hadValue
+}
+
+func isEmptyCollection(v any) bool {
+	switch val := v.(type) {
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	default:
+		return v == nil
+	}
+}
+
+// checkNodeTemplateVersion fails when the template was changed since it was last read into the state. It's a best
+// effort check: the API doesn't support conditional updates, so a change made after the check is still overwritten.
+func checkNodeTemplateVersion(ctx context.Context, d *schema.ResourceData, meta any, clusterID string) diag.Diagnostics {
+	expected, _ := d.GetChange(FieldNodeTemplateVersion)
+	if expected.(string) == "" {
+		return nil
+	}
+
+	current, err := getNodeTemplateByName(ctx, d, meta, clusterID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if actual := lo.FromPtr(current.Version); actual != expected.(string) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  "Node template was modified outside of this configuration",
+			Detail: fmt.Sprintf("Node template %q is at version %q, but version %q was expected. "+
+				"Run `terraform apply -refresh-only` (or `terraform refresh`) to fetch the latest state and review the changes before applying again.",
+				d.Id(), actual, expected.(string)),
+			AttributePath: cty.GetAttrPath(FieldNodeTemplateVersion),
+		}}
+	}
+
+	return nil
 }
 
 func resourceNodeTemplateCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
 	log.Printf("[INFO] Create Node Template post call start")
 	defer log.Printf("[INFO] Create Node Template post call end")
 	client := meta.(*ProviderConfig).api
-	clusterID := d.Get(FieldClusterID).(string)
+	clusterID, err := resolveClusterID(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	req := sdk.NodeTemplatesAPICreateNodeTemplateJSONRequestBody{
 		Name:            lo.ToPtr(d.Get(FieldNodeTemplateName).(string)),
 		ConfigurationId: lo.ToPtr(d.Get(FieldNodeTemplateConfigurationId).(string)),
@@ -552,89 +1133,189 @@ func resourceNodeTemplateCreate(ctx context.Context, d *schema.ResourceData, met
 		}
 	}
 
-	if v, ok := d.Get(FieldNodeTemplateCustomLabel).([]any); ok && len(v) > 0 {
-		req.CustomLabel = toCustomLabel(v[0].(map[string]any))
-	}
-
-	if req.CustomLabel == nil {
-		if v, ok := d.Get(FieldNodeTemplateCustomLabels).(map[string]any); ok && len(v) > 0 {
-			customLabels := map[string]string{}
-
-			for k, v := range v {
-				customLabels[k] = v.(string)
-			}
-
-			req.CustomLabels = &sdk.NodetemplatesV1NewNodeTemplate_CustomLabels{AdditionalProperties: customLabels}
-		}
+	if labels := toCustomLabels(d); len(labels) > 0 {
+		req.CustomLabels = &sdk.NodetemplatesV1NewNodeTemplate_CustomLabels{AdditionalProperties: labels}
 	}
 
-	if v, ok := d.Get(FieldNodeTemplateCustomTaints).([]any); ok && len(v) > 0 {
-		ts := []map[string]any{}
-		for _, val := range v {
-			ts = append(ts, val.(map[string]any))
-		}
+	req.CustomTaints = toTaints(d)
 
-		req.CustomTaints = toCustomTaintsWithoutEffect(ts)
+	if v, ok := d.Get(FieldNodeTemplateConstraints).([]any); ok && len(v) > 0 {
+		req.Constraints = toTemplateConstraints(withConfiguredMinValues(v[0].(map[string]any), nodeTemplateConstraintsConfig(d)))
 	}
 
-	if !(*req.ShouldTaint) && req.CustomTaints != nil && len(*req.CustomTaints) > 0 {
-		return diag.FromErr(fmt.Errorf("shouldTaint must be true for the node template to get created with custom taints"))
+	if v, _ := d.GetOk(FieldNodeTemplateCustomInstancesEnabled); v != nil {
+		req.CustomInstancesEnabled = lo.ToPtr(v.(bool))
 	}
 
-	if v, ok := d.Get(FieldNodeTemplateConstraints).([]any); ok && len(v) > 0 {
-		req.Constraints = toTemplateConstraints(v[0].(map[string]any))
+	if v, ok := d.GetOk(FieldNodeTemplateCloneFrom); ok {
+		source, err := findNodeTemplateByName(ctx, meta, clusterID, v.(string))
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("getting node template to clone from: %w", err))
+		}
+		mergeClonedSettings(d, &req, source)
 	}
 
-	if v, _ := d.GetOk(FieldNodeTemplateCustomInstancesEnabled); v != nil {
-		req.CustomInstancesEnabled = lo.ToPtr(v.(bool))
+	// Template is created without taint unless it's requested explicitly.
+	if err := validateTaints(lo.ToPtr(lo.FromPtr(req.ShouldTaint)), req.CustomTaints); err != nil {
+		return diag.FromErr(err)
 	}
 
 	resp, err := client.NodeTemplatesAPICreateNodeTemplateWithResponse(ctx, clusterID, req)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeTemplates, clusterID))
 	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
 		return diag.FromErr(checkErr)
 	}
 
 	d.SetId(lo.FromPtr(resp.JSON200.Name))
 
-	return resourceNodeTemplateRead(ctx, d, meta)
+	template, diags := readNodeTemplate(ctx, d, meta)
+	if template != nil && !diags.HasError() {
+		diags = append(diags, setMatchedInstanceTypes(ctx, d, meta, template)...)
+	}
+
+	return append(diags, customLabelDeprecationWarning(d)...)
 }
 
 func getNodeTemplateByName(ctx context.Context, data *schema.ResourceData, meta any, clusterID sdk.ClusterId) (*sdk.NodetemplatesV1NodeTemplate, error) {
-	client := meta.(*ProviderConfig).api
-	nodeTemplateName := data.Id()
+	return findNodeTemplateByName(ctx, meta, clusterID, data.Id())
+}
 
-	log.Printf("[INFO] Getting current node templates")
-	resp, err := client.NodeTemplatesAPIListNodeTemplatesWithResponse(ctx, clusterID)
-	notFound := fmt.Errorf("node templates for cluster %q not found at CAST AI", clusterID)
+func findNodeTemplateByName(ctx context.Context, meta any, clusterID sdk.ClusterId, nodeTemplateName string) (*sdk.NodetemplatesV1NodeTemplate, error) {
+	item, err := findNodeTemplateListItem(ctx, meta, clusterID, nodeTemplateName)
 	if err != nil {
 		return nil, err
 	}
 
-	templates := resp.JSON200
+	return item.Template, nil
+}
 
-	if templates == nil {
-		return nil, notFound
+// findNodeTemplateListItem returns the node template together with stats of its nodes.
+func findNodeTemplateListItem(ctx context.Context, meta any, clusterID sdk.ClusterId, nodeTemplateName string) (*sdk.NodetemplatesV1NodeTemplateListItem, error) {
+	log.Printf("[INFO] Getting current node templates")
+	resp, err := listNodeTemplates(ctx, meta, clusterID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
-		log.Printf("[WARN] Getting current node template: %v", err)
-		return nil, fmt.Errorf("failed to get current node template from API: %v", err)
+	if isNotFound(resp) {
+		return nil, newNotFoundError("node templates for cluster %q not found at CAST AI", clusterID)
 	}
+	if err := sdk.CheckOKResponse(resp, err); err != nil {
+		return nil, err
+	}
+
+	templates := resp.JSON200
 
 	t, ok := lo.Find[sdk.NodetemplatesV1NodeTemplateListItem](lo.FromPtr(templates.Items), func(t sdk.NodetemplatesV1NodeTemplateListItem) bool {
 		return lo.FromPtr(t.Template.Name) == nodeTemplateName
 	})
 
 	if !ok {
-		return nil, fmt.Errorf("failed to find node template with name: %v", nodeTemplateName)
+		return nil, newNotFoundError("failed to find node template with name: %v", nodeTemplateName)
+	}
+
+	return &t, nil
+}
+
+func flattenNodeTemplateStats(stats *sdk.NodetemplatesV1NodeTemplateListItemStats) []map[string]any {
+	if stats == nil {
+		return nil
+	}
+
+	onDemand, spot, fallback := lo.FromPtr(stats.CountOnDemand), lo.FromPtr(stats.CountSpot), lo.FromPtr(stats.CountFallback)
+	return []map[string]any{{
+		"nodes":           onDemand + spot + fallback,
+		"on_demand_nodes": onDemand,
+		"spot_nodes":      spot,
+		"fallback_nodes":  fallback,
+	}}
+}
+
+// listNodeTemplates lists node templates of the cluster. Successful responses are cached by the provider, so refresh of
+// many templates of the same cluster makes a single list call.
+func listNodeTemplates(ctx context.Context, meta any, clusterID sdk.ClusterId) (*sdk.NodeTemplatesAPIListNodeTemplatesResponse, error) {
+	config := meta.(*ProviderConfig)
+
+	return getCachedResponse(config.responses, listCacheKey(listCacheEndpointNodeTemplates, clusterID), func() (*sdk.NodeTemplatesAPIListNodeTemplatesResponse, error) {
+		return fetchWithRetry(ctx, func() (*sdk.NodeTemplatesAPIListNodeTemplatesResponse, error) {
+			return config.api.NodeTemplatesAPIListNodeTemplatesWithResponse(ctx, clusterID)
+		})
+	})
+}
+
+// mergeClonedSettings copies settings of the source template which are not set in the configuration into the create
+// request.
+func mergeClonedSettings(d *schema.ResourceData, req *sdk.NodeTemplatesAPICreateNodeTemplateJSONRequestBody, source *sdk.NodetemplatesV1NodeTemplate) {
+	if !isSetInConfig(d, FieldNodeTemplateConfigurationId) {
+		req.ConfigurationId = source.ConfigurationId
 	}
+	if !isSetInConfig(d, FieldNodeTemplateShouldTaint) {
+		req.ShouldTaint = source.ShouldTaint
+	}
+	if !isSetInConfig(d, FieldNodeTemplateRebalancingConfigMinNodes) {
+		req.RebalancingConfig = source.RebalancingConfig
+	}
+	if !isSetInConfig(d, FieldNodeTemplateCustomLabel) && !isSetInConfig(d, FieldNodeTemplateCustomLabels) {
+		if labels := withCustomLabel(lo.FromPtr(source.CustomLabels).AdditionalProperties, source.CustomLabel); len(labels) > 0 {
+			req.CustomLabels = &sdk.NodetemplatesV1NewNodeTemplate_CustomLabels{AdditionalProperties: labels}
+		}
+	}
+	if !isSetInConfig(d, FieldNodeTemplateCustomTaints) && source.CustomTaints != nil {
+		req.CustomTaints = lo.ToPtr(lo.Map(*source.CustomTaints, func(t sdk.NodetemplatesV1Taint, _ int) sdk.NodetemplatesV1TaintWithoutEffect {
+			return sdk.NodetemplatesV1TaintWithoutEffect{Key: t.Key, Value: t.Value}
+		}))
+	}
+	if !isSetInConfig(d, FieldNodeTemplateConstraints) {
+		req.Constraints = source.Constraints
+	}
+	if !isSetInConfig(d, FieldNodeTemplateCustomInstancesEnabled) {
+		req.CustomInstancesEnabled = source.CustomInstancesEnabled
+	}
+}
 
+// dropClonedSettings removes settings copied from clone_from template, so they don't show up as changes to remove.
+func dropClonedSettings(d *schema.ResourceData, t *sdk.NodetemplatesV1NodeTemplate) {
+	if inheritedFromClone(d, FieldNodeTemplateConfigurationId) {
+		t.ConfigurationId = nil
+	}
+	if inheritedFromClone(d, FieldNodeTemplateShouldTaint) {
+		t.ShouldTaint = nil
+	}
+	if inheritedFromClone(d, FieldNodeTemplateRebalancingConfigMinNodes) {
+		t.RebalancingConfig = nil
+	}
+	if inheritedFromClone(d, FieldNodeTemplateCustomLabel) && inheritedFromClone(d, FieldNodeTemplateCustomLabels) {
+		t.CustomLabel = nil
+		t.CustomLabels = &sdk.NodetemplatesV1NodeTemplate_CustomLabels{}
+	}
+	if inheritedFromClone(d, FieldNodeTemplateCustomTaints) {
+		t.CustomTaints = nil
+	}
+	if inheritedFromClone(d, FieldNodeTemplateConstraints) {
+		t.Constraints = nil
+	}
+	if inheritedFromClone(d, FieldNodeTemplateCustomInstancesEnabled) {
+		t.CustomInstancesEnabled = nil
+	}
+}
+
+// copyNodeTemplate returns a deep copy of the template, so it can be modified without changing cached responses.
+func copyNodeTemplate(t *sdk.NodetemplatesV1NodeTemplate) (*sdk.NodetemplatesV1NodeTemplate, error) {
+	raw, err := json.Marshal(t)
 	if err != nil {
-		log.Printf("[WARN] Failed merging node template changes: %v", err)
-		return nil, fmt.Errorf("failed to merge node template changes: %v", err)
+		return nil, fmt.Errorf("copying node template: %w", err)
+	}
+	var out sdk.NodetemplatesV1NodeTemplate
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("copying node template: %w", err)
 	}
 
-	return t.Template, nil
+	return &out, nil
+}
+
+// inheritedFromClone returns true when the field value was copied from clone_from template instead of being configured.
+func inheritedFromClone(d *schema.ResourceData, key string) bool {
+	return d.Get(FieldNodeTemplateCloneFrom).(string) != "" && !isSetInConfig(d, key)
 }
 
 func nodeTemplateStateImporter(ctx context.Context, d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
@@ -647,6 +1328,10 @@ func nodeTemplateStateImporter(ctx context.Context, d *schema.ResourceData, meta
 	if err := d.Set(FieldClusterID, clusterID); err != nil {
 		return nil, fmt.Errorf("setting cluster id: %w", err)
 	}
+	// force_delete isn't returned by the API, it's set to its default so imported templates don't show a change.
+	if err := d.Set(FieldNodeTemplateForceDelete, false); err != nil {
+		return nil, fmt.Errorf("setting force delete: %w", err)
+	}
 	d.SetId(id)
 
 	// Return if node config ID provided.
@@ -655,8 +1340,7 @@ func nodeTemplateStateImporter(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	// Find node templates
-	client := meta.(*ProviderConfig).api
-	resp, err := client.NodeTemplatesAPIListNodeTemplatesWithResponse(ctx, clusterID)
+	resp, err := listNodeTemplates(ctx, meta, clusterID)
 	if err != nil {
 		return nil, err
 	}
@@ -674,6 +1358,69 @@ func nodeTemplateStateImporter(ctx context.Context, d *schema.ResourceData, meta
 	return nil, fmt.Errorf("failed to find node template with the following name: %v", id)
 }
 
+// toCustomLabels returns labels of custom_labels together with the label of the deprecated custom_label. Only custom
+// labels are sent to the API, the label of custom_label takes precedence as it did when it was sent on its own.
+func toCustomLabels(d *schema.ResourceData) map[string]string {
+	labels := map[string]string{}
+	for k, v := range d.Get(FieldNodeTemplateCustomLabels).(map[string]any) {
+		labels[k] = v.(string)
+	}
+
+	return withCustomLabel(labels, configuredCustomLabel(d))
+}
+
+// configuredCustomLabel returns the label of the deprecated custom_label, nil is returned when it's not set.
+func configuredCustomLabel(d *schema.ResourceData) *sdk.NodetemplatesV1Label {
+	v, ok := d.Get(FieldNodeTemplateCustomLabel).([]any)
+	if !ok || len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	return toCustomLabel(v[0].(map[string]any))
+}
+
+// customLabelDeprecationWarning returns a warning with custom_labels block replacing the configured custom_label, so the
+// configuration can be migrated by copying it. Nothing is returned when custom_label isn't configured.
+func customLabelDeprecationWarning(d *schema.ResourceData) diag.Diagnostics {
+	label := configuredCustomLabel(d)
+	if label == nil || label.Key == nil || !isSetInConfig(d, FieldNodeTemplateCustomLabel) {
+		return nil
+	}
+
+	labels := lo.MapEntries(withCustomLabel(toStringMap(d.Get(FieldNodeTemplateCustomLabels).(map[string]any)), label), func(k, v string) (string, string) {
+		return strconv.Quote(k), strconv.Quote(v)
+	})
+	keys := lo.Keys(labels)
+	sort.Strings(keys)
+	width := lo.Max(lo.Map(keys, func(k string, _ int) int { return len(k) }))
+
+	var hcl strings.Builder
+	fmt.Fprintf(&hcl, "  %s = {\n", FieldNodeTemplateCustomLabels)
+	for _, k := range keys {
+		fmt.Fprintf(&hcl, "    %-*s = %s\n", width, k, labels[k])
+	}
+	hcl.WriteString("  }")
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("`%s` is deprecated, use `%s` instead", FieldNodeTemplateCustomLabel, FieldNodeTemplateCustomLabels),
+		Detail: fmt.Sprintf("Node template %q sets label %q with the deprecated `%s` block, it will be removed in the next major version. "+
+			"Replace `%s` and `%s` of the node template with:\n\n%s\n\nLabels of the node template don't change, so the migration doesn't cause any changes to nodes.",
+			d.Id(), *label.Key, FieldNodeTemplateCustomLabel, FieldNodeTemplateCustomLabel, FieldNodeTemplateCustomLabels, hcl.String()),
+		AttributePath: cty.GetAttrPath(FieldNodeTemplateCustomLabel),
+	}}
+}
+
+// withCustomLabel returns a copy of labels with the label added.
+func withCustomLabel(labels map[string]string, label *sdk.NodetemplatesV1Label) map[string]string {
+	out := lo.Assign(labels)
+	if label != nil && label.Key != nil {
+		out[*label.Key] = lo.FromPtr(label.Value)
+	}
+
+	return out
+}
+
 func toCustomLabel(obj map[string]any) *sdk.NodetemplatesV1Label {
 	if obj == nil {
 		return nil
@@ -690,6 +1437,31 @@ func toCustomLabel(obj map[string]any) *sdk.NodetemplatesV1Label {
 	return out
 }
 
+// toTaints returns custom taints of the resource in API representation, nil is returned when no taints are set.
+func toTaints(d *schema.ResourceData) *[]sdk.NodetemplatesV1TaintWithoutEffect {
+	v, ok := d.Get(FieldNodeTemplateCustomTaints).([]any)
+	if !ok || len(v) == 0 {
+		return nil
+	}
+
+	ts := make([]map[string]any, 0, len(v))
+	for _, val := range v {
+		ts = append(ts, val.(map[string]any))
+	}
+
+	return toCustomTaintsWithoutEffect(ts)
+}
+
+// validateTaints checks that custom taints are only requested for tainted nodes. Nil shouldTaint means the flag is not
+// changed by the request, so it can't be validated locally.
+func validateTaints(shouldTaint *bool, customTaints *[]sdk.NodetemplatesV1TaintWithoutEffect) error {
+	if shouldTaint == nil || *shouldTaint || customTaints == nil || len(*customTaints) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("shouldTaint must be true for the node template to have custom taints")
+}
+
 func toCustomTaintsWithoutEffect(objs []map[string]any) *[]sdk.NodetemplatesV1TaintWithoutEffect {
 	if len(objs) == 0 {
 		return nil
@@ -773,13 +1545,22 @@ func toTemplateConstraints(obj map[string]any) *sdk.NodetemplatesV1TemplateConst
 	if v, ok := obj["max_cpu"].(int); ok && v != 0 {
 		out.MaxCpu = toPtr(int32(v))
 	}
+	normalizeMemory, _ := obj["normalize_memory"].(bool)
 	if v, ok := obj["max_memory"].(int); ok && v != 0 {
+		if normalizeMemory {
+			v = roundDownMemory(v)
+		}
 		out.MaxMemory = toPtr(int32(v))
 	}
+	// Min values are always serialized, missing ones as null which clears the constraint. Use withConfiguredMinValues
+	// to drop min values which aren't set in the configuration, so an explicit 0 is still sent as a constraint.
 	if v, ok := obj["min_cpu"].(int); ok {
 		out.MinCpu = toPtr(int32(v))
 	}
 	if v, ok := obj["min_memory"].(int); ok {
+		if normalizeMemory {
+			v = roundUpMemory(v)
+		}
 		out.MinMemory = toPtr(int32(v))
 	}
 	if v, ok := obj["spot"].(bool); ok {
@@ -791,8 +1572,45 @@ func toTemplateConstraints(obj map[string]any) *sdk.NodetemplatesV1TemplateConst
 	if v, ok := obj["use_spot_fallbacks"].(bool); ok {
 		out.UseSpotFallbacks = toPtr(v)
 	}
-	if v, ok := obj["architectures"].([]any); ok {
-		out.Architectures = toPtr(toStringList(v))
+	if v, ok := obj["architectures"].(*schema.Set); ok {
+		out.Architectures = toPtr(toSortedStringList(v))
+	}
+
+	return out
+}
+
+// nodeTemplateConstraintsConfig returns the raw configuration of the constraints block, or a null value when it's not
+// available, e.g. during refresh.
+func nodeTemplateConstraintsConfig(d configReader) cty.Value {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() || !raw.Type().IsObjectType() || !raw.Type().HasAttribute(FieldNodeTemplateConstraints) {
+		return cty.NilVal
+	}
+	constraints := raw.GetAttr(FieldNodeTemplateConstraints)
+	if constraints.IsNull() || !constraints.IsKnown() || !constraints.CanIterateElements() || constraints.LengthInt() == 0 {
+		return cty.NilVal
+	}
+
+	return constraints.Index(cty.NumberIntVal(0))
+}
+
+// withConfiguredMinValues drops min_cpu and min_memory from the constraints unless they are set in the raw
+// configuration, as unset values read as 0 too. Without configuration only non zero values are kept.
+func withConfiguredMinValues(obj map[string]any, config cty.Value) map[string]any {
+	out := make(map[string]any, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+	for _, key := range []string{"min_cpu", "min_memory"} {
+		if config == cty.NilVal || config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(key) {
+			if v, _ := out[key].(int); v == 0 {
+				delete(out, key)
+			}
+			continue
+		}
+		if config.GetAttr(key).IsNull() {
+			delete(out, key)
+		}
 	}
 
 	return out
@@ -804,11 +1622,11 @@ func toTemplateConstraintsInstanceFamilies(o map[string]any) *sdk.NodetemplatesV
 	}
 
 	out := &sdk.NodetemplatesV1TemplateConstraintsInstanceFamilyConstraints{}
-	if v, ok := o["exclude"].([]any); ok {
-		out.Exclude = toPtr(toStringList(v))
+	if v, ok := o["exclude"].(*schema.Set); ok {
+		out.Exclude = toPtr(toSortedStringList(v))
 	}
-	if v, ok := o["include"].([]any); ok {
-		out.Include = toPtr(toStringList(v))
+	if v, ok := o["include"].(*schema.Set); ok {
+		out.Include = toPtr(toSortedStringList(v))
 	}
 	return out
 }
@@ -819,8 +1637,8 @@ func toTemplateConstraintsGpuConstraints(o map[string]any) *sdk.NodetemplatesV1T
 	}
 
 	out := &sdk.NodetemplatesV1TemplateConstraintsGPUConstraints{}
-	if v, ok := o["manufacturers"].([]any); ok {
-		out.Manufacturers = toPtr(toStringList(v))
+	if v, ok := o["manufacturers"].(*schema.Set); ok {
+		out.Manufacturers = toPtr(toSortedStringList(v))
 	}
 
 	if v, ok := o["exclude_names"].([]any); ok {
@@ -3,17 +3,22 @@ package castai
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 
 	"github.com/castai/terraform-provider-castai/castai/sdk"
@@ -37,6 +42,7 @@ func TestNodeTemplateResourceReadContext(t *testing.T) {
 		{
 		  "items": [
 			{
+			  "stats": {"countOnDemand": 2, "countSpot": 3, "countFallback": 1},
 			  "template": {
 				"configurationId": "7dc4f922-29c9-4377-889c-0c8c5fb8d497",
 				"configurationName": "default",
@@ -103,7 +109,7 @@ func TestNodeTemplateResourceReadContext(t *testing.T) {
 
 	resource := resourceNodeTemplate()
 	val := cty.ObjectVal(map[string]cty.Value{
-		FieldClusterId:        cty.StringVal(clusterId),
+		FieldClusterID:        cty.StringVal(clusterId),
 		FieldNodeTemplateName: cty.StringVal("gpu"),
 	})
 	state := terraform.NewInstanceStateShimmedFromValue(val, 0)
@@ -118,31 +124,33 @@ cluster_id = b6bfc074-a267-400f-b8f1-db0850c369b1
 configuration_id = 7dc4f922-29c9-4377-889c-0c8c5fb8d497
 constraints.# = 1
 constraints.0.architectures.# = 2
-constraints.0.architectures.0 = amd64
-constraints.0.architectures.1 = arm64
+constraints.0.architectures.2370514181 = amd64
+constraints.0.architectures.317940444 = arm64
 constraints.0.compute_optimized = false
 constraints.0.fallback_restore_rate_seconds = 0
 constraints.0.gpu.# = 1
 constraints.0.gpu.0.exclude_names.# = 0
 constraints.0.gpu.0.include_names.# = 0
 constraints.0.gpu.0.manufacturers.# = 1
-constraints.0.gpu.0.manufacturers.0 = NVIDIA
+constraints.0.gpu.0.manufacturers.892923597 = NVIDIA
 constraints.0.gpu.0.max_count = 0
 constraints.0.gpu.0.min_count = 0
 constraints.0.instance_families.# = 1
 constraints.0.instance_families.0.exclude.# = 7
-constraints.0.instance_families.0.exclude.0 = p4d
-constraints.0.instance_families.0.exclude.1 = p3dn
-constraints.0.instance_families.0.exclude.2 = p2
-constraints.0.instance_families.0.exclude.3 = g3s
-constraints.0.instance_families.0.exclude.4 = g5g
-constraints.0.instance_families.0.exclude.5 = g5
-constraints.0.instance_families.0.exclude.6 = g3
+constraints.0.instance_families.0.exclude.1037446076 = g5
+constraints.0.instance_families.0.exclude.1514530804 = p4d
+constraints.0.instance_families.0.exclude.2411610369 = g3s
+constraints.0.instance_families.0.exclude.2788244105 = p2
+constraints.0.instance_families.0.exclude.3275633402 = g5g
+constraints.0.instance_families.0.exclude.3352151733 = p3dn
+constraints.0.instance_families.0.exclude.3568668297 = g3
 constraints.0.instance_families.0.include.# = 0
 constraints.0.max_cpu = 10000
 constraints.0.max_memory = 0
 constraints.0.min_cpu = 10
 constraints.0.min_memory = 0
+constraints.0.normalize_memory = false
+constraints.0.skip_instance_families_validation = false
 constraints.0.spot = false
 constraints.0.storage_optimized = false
 constraints.0.use_spot_fallbacks = false
@@ -161,10 +169,886 @@ custom_taints.1.value = some-value-2
 name = gpu
 rebalancing_config_min_nodes = 0
 should_taint = true
+stats.# = 1
+stats.0.fallback_nodes = 1
+stats.0.nodes = 6
+stats.0.on_demand_nodes = 2
+stats.0.spot_nodes = 3
+version = 3
 Tainted = false
 `, data.State().String())
 }
 
+func TestNodeTemplateResourceUpdateVersionConflict(t *testing.T) {
+	r := require.New(t)
+	mockctrl := gomock.NewController(t)
+	mockClient := mock_sdk.NewMockClientInterface(mockctrl)
+
+	ctx := context.Background()
+	provider := &ProviderConfig{
+		api: &sdk.ClientWithResponses{
+			ClientInterface: mockClient,
+		},
+	}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	body := io.NopCloser(bytes.NewReader([]byte(`{"items": [{"template": {"name": "gpu", "version": "4"}}]}`)))
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(&http.Response{StatusCode: 200, Body: body, Header: map[string][]string{"Content-Type": {"json"}}}, nil)
+
+	resource := resourceNodeTemplate()
+	val := cty.ObjectVal(map[string]cty.Value{
+		FieldClusterID:           cty.StringVal(clusterId),
+		FieldNodeTemplateName:    cty.StringVal("gpu"),
+		FieldNodeTemplateVersion: cty.StringVal("3"),
+	})
+	state := terraform.NewInstanceStateShimmedFromValue(val, 0)
+	state.ID = "gpu"
+
+	data := resource.Data(state)
+	result := checkNodeTemplateVersion(ctx, data, provider, clusterId)
+	r.NotNil(result)
+	r.True(result.HasError())
+	r.Equal("Node template was modified outside of this configuration", result[0].Summary)
+	r.Contains(result[0].Detail, `version "4", but version "3" was expected`)
+}
+
+func TestNodeTemplateResourceReadContextCachesList(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{
+		api:       &sdk.ClientWithResponses{ClientInterface: mockClient},
+		responses: newResponseCache(listCacheTTL),
+	}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	list := `{"items": [{"template": {"name": "a", "customLabels": {}}}, {"template": {"name": "b", "customLabels": {}}}]}`
+	// Templates are listed once for both reads and once more after the delete.
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		DoAndReturn(func(_ context.Context, _ string, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			return testJSONResponse(http.StatusOK, list), nil
+		}).Times(2)
+	mockClient.EXPECT().
+		NodeTemplatesAPIDeleteNodeTemplate(gomock.Any(), clusterId, "a").
+		Return(testJSONResponse(http.StatusOK, `{}`), nil)
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil
+		}).AnyTimes()
+
+	resource := resourceNodeTemplate()
+	read := func(name string) *schema.ResourceData {
+		state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+			FieldClusterID:        cty.StringVal(clusterId),
+			FieldNodeTemplateName: cty.StringVal(name),
+		}), 0)
+		state.ID = name
+		data := resource.Data(state)
+		r.Nil(resource.ReadContext(context.Background(), data, provider))
+		return data
+	}
+
+	a := read("a")
+	r.Equal("a", a.Id())
+	r.Equal("b", read("b").Id())
+
+	r.Nil(resource.DeleteContext(context.Background(), a, provider))
+	r.Equal("b", read("b").Id())
+}
+
+func TestNodeTemplateResourceReadContextCloneFromKeepsCachedList(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{
+		api:       &sdk.ClientWithResponses{ClientInterface: mockClient},
+		responses: newResponseCache(listCacheTTL),
+	}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [{"template": {
+			"name": "gpu-large",
+			"shouldTaint": true,
+			"customLabels": {"team": "ml"},
+			"customTaints": [{"key": "gpu", "value": "true", "effect": "NoSchedule"}],
+			"constraints": {"spot": true}
+		}}]}`), nil)
+
+	resource := resourceNodeTemplate()
+	state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+		FieldClusterID:             cty.StringVal(clusterId),
+		FieldNodeTemplateName:      cty.StringVal("gpu-large"),
+		FieldNodeTemplateCloneFrom: cty.StringVal("gpu"),
+	}), 0)
+	state.ID = "gpu-large"
+	data := resource.Data(state)
+	r.Nil(resource.ReadContext(context.Background(), data, provider))
+	r.Empty(data.Get(FieldNodeTemplateCustomLabels))
+	r.Empty(data.Get(FieldNodeTemplateConstraints))
+
+	// Settings inherited from clone_from are dropped from the state only, the cached list still has them.
+	resp, err := listNodeTemplates(context.Background(), provider, clusterId)
+	r.NoError(err)
+	cached := lo.FromPtr(resp.JSON200.Items)[0].Template
+	r.True(lo.FromPtr(cached.ShouldTaint))
+	r.Equal(map[string]string{"team": "ml"}, cached.CustomLabels.AdditionalProperties)
+	r.Len(lo.FromPtr(cached.CustomTaints), 1)
+	r.True(lo.FromPtr(cached.Constraints.Spot))
+}
+
+func TestNodeTemplateResourceMemoryDiff(t *testing.T) {
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil
+		}).AnyTimes()
+
+	tests := map[string]struct {
+		constraints map[string]any
+		expectedErr string
+	}{
+		"multiples of granularity": {
+			constraints: map[string]any{"min_memory": 2048, "max_memory": 8192},
+		},
+		"invalid min memory": {
+			constraints: map[string]any{"min_memory": 2000},
+			expectedErr: "constraints.0.min_memory must be a multiple of 1024 MiB, got 2000",
+		},
+		"invalid max memory": {
+			constraints: map[string]any{"min_memory": 1024, "max_memory": 5000},
+			expectedErr: "constraints.0.max_memory must be a multiple of 1024 MiB, got 5000",
+		},
+		"normalized memory": {
+			constraints: map[string]any{"min_memory": 2000, "max_memory": 5000, "normalize_memory": true},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			_, err := resourceNodeTemplate().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]any{
+				FieldClusterID:               clusterId,
+				FieldNodeTemplateName:        "gpu",
+				FieldNodeTemplateConstraints: []any{tt.constraints},
+			}), provider)
+			if tt.expectedErr != "" {
+				r.Error(err)
+				r.Contains(err.Error(), tt.expectedErr)
+				return
+			}
+			r.NoError(err)
+		})
+	}
+}
+
+func TestNodeTemplateResourceConstraintRangesDiff(t *testing.T) {
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil
+		}).AnyTimes()
+
+	tests := map[string]struct {
+		constraints map[string]any
+		expectedErr string
+	}{
+		"valid ranges": {
+			constraints: map[string]any{
+				"min_cpu": 4, "max_cpu": 4, "min_memory": 2048, "max_memory": 8192,
+				"gpu": []any{map[string]any{"min_count": 1, "max_count": 2}},
+			},
+		},
+		"max not set": {
+			constraints: map[string]any{"min_cpu": 4, "min_memory": 2048, "gpu": []any{map[string]any{"min_count": 1}}},
+		},
+		"max cpu lower than min": {
+			constraints: map[string]any{"min_cpu": 8, "max_cpu": 4},
+			expectedErr: "constraints.0.max_cpu (4) must be greater than or equal to constraints.0.min_cpu (8)",
+		},
+		"max memory lower than min": {
+			constraints: map[string]any{"min_memory": 8192, "max_memory": 4096},
+			expectedErr: "constraints.0.max_memory (4096) must be greater than or equal to constraints.0.min_memory (8192)",
+		},
+		"max memory lower than min after normalization": {
+			constraints: map[string]any{"min_memory": 1500, "max_memory": 2000, "normalize_memory": true},
+			expectedErr: "constraints.0.max_memory (2000) must be greater than or equal to constraints.0.min_memory (1500) after normalization",
+		},
+		"max gpu count lower than min": {
+			constraints: map[string]any{"gpu": []any{map[string]any{"min_count": 4, "max_count": 2}}},
+			expectedErr: "constraints.0.gpu.0.max_count (2) must be greater than or equal to constraints.0.gpu.0.min_count (4)",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			_, err := resourceNodeTemplate().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]any{
+				FieldClusterID:               clusterId,
+				FieldNodeTemplateName:        "gpu",
+				FieldNodeTemplateConstraints: []any{tt.constraints},
+			}), provider)
+			if tt.expectedErr != "" {
+				r.Error(err)
+				r.Contains(err.Error(), tt.expectedErr)
+				return
+			}
+			r.NoError(err)
+		})
+	}
+}
+
+func TestNodeTemplateResourceInstanceFamiliesDiff(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	tests := map[string]struct {
+		constraints  map[string]any
+		response     *http.Response
+		expectedErr  string
+		expectFilter bool
+	}{
+		"known families": {
+			constraints:  map[string]any{"instance_families": []any{map[string]any{"include": []any{"m5a"}, "exclude": []any{"c5"}}}},
+			response:     testJSONResponse(http.StatusOK, `{"availableInstanceTypes": [{"name": "m5a.large", "family": "m5a"}, {"name": "c5.large", "family": "c5"}]}`),
+			expectFilter: true,
+		},
+		"unknown families": {
+			constraints:  map[string]any{"instance_families": []any{map[string]any{"include": []any{"m5a", "m5ax"}, "exclude": []any{"c5x"}}}},
+			response:     testJSONResponse(http.StatusOK, `{"availableInstanceTypes": [{"name": "m5a.large", "family": "m5a"}, {"name": "c5.large", "family": "c5"}]}`),
+			expectedErr:  "instance families m5ax, c5x are not available for cluster",
+			expectFilter: true,
+		},
+		"instance types can't be listed": {
+			constraints:  map[string]any{"instance_families": []any{map[string]any{"include": []any{"m5ax"}}}},
+			response:     testJSONResponse(http.StatusInternalServerError, `{"message": "internal error"}`),
+			expectFilter: true,
+		},
+		"validation skipped": {
+			constraints: map[string]any{
+				"instance_families":                 []any{map[string]any{"include": []any{"m5ax"}}},
+				"skip_instance_families_validation": true,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			var familiesListed bool
+			mockClient.EXPECT().
+				NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, req sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+					// Families are validated against all instance types of the cluster, matched instance types use the families filter.
+					if req.Constraints.InstanceFamilies == nil {
+						r.ElementsMatch([]string{ArchAMD64, ArchARM64}, lo.FromPtr(req.Constraints.Architectures))
+						familiesListed = true
+						return tt.response, nil
+					}
+					return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil
+				}).AnyTimes()
+			t.Cleanup(func() {
+				r.Equal(tt.expectFilter, familiesListed)
+			})
+
+			_, err := resourceNodeTemplate().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]any{
+				FieldClusterID:               clusterId,
+				FieldNodeTemplateName:        "gpu",
+				FieldNodeTemplateConstraints: []any{tt.constraints},
+			}), provider)
+			if tt.expectedErr != "" {
+				r.Error(err)
+				r.Contains(err.Error(), tt.expectedErr)
+				return
+			}
+			r.NoError(err)
+		})
+	}
+}
+
+func TestNodeTemplateResourceNormalizedMemoryDiffSuppressed(t *testing.T) {
+	r := require.New(t)
+	resource := resourceNodeTemplate()
+
+	data := resource.TestResourceData()
+	r.NoError(data.Set(FieldNodeTemplateConstraints, []any{map[string]any{"normalize_memory": true}}))
+
+	suppress := resource.Schema[FieldNodeTemplateConstraints].Elem.(*schema.Resource).Schema["min_memory"].DiffSuppressFunc
+	r.True(suppress("constraints.0.min_memory", "2048", "2000", data))
+	r.False(suppress("constraints.0.min_memory", "2048", "3000", data))
+	r.True(suppress("constraints.0.max_memory", "4096", "5000", data))
+
+	r.NoError(data.Set(FieldNodeTemplateConstraints, []any{map[string]any{"normalize_memory": false}}))
+	r.False(suppress("constraints.0.min_memory", "2048", "2000", data))
+}
+
+func TestToTemplateConstraintsNormalizedMemory(t *testing.T) {
+	r := require.New(t)
+
+	constraints := toTemplateConstraints(map[string]any{
+		"min_memory":       2000,
+		"max_memory":       5000,
+		"normalize_memory": true,
+	})
+	r.Equal(int32(2048), lo.FromPtr(constraints.MinMemory))
+	r.Equal(int32(4096), lo.FromPtr(constraints.MaxMemory))
+
+	// Max memory is never rounded down to 0, which would remove the constraint.
+	constraints = toTemplateConstraints(map[string]any{
+		"max_memory":       512,
+		"normalize_memory": true,
+	})
+	r.Equal(int32(1024), lo.FromPtr(constraints.MaxMemory))
+
+	constraints = toTemplateConstraints(map[string]any{
+		"min_memory": 2000,
+	})
+	r.Equal(int32(2000), lo.FromPtr(constraints.MinMemory))
+}
+
+func TestNodeTemplateResourceMatchedInstanceTypesDiff(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal(int32(64), lo.FromPtr(req.Constraints.MinCpu))
+
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": [{"name": "m5.24xlarge"}, {"name": "c5.18xlarge"}]}`), nil
+		}).AnyTimes()
+
+	resource := resourceNodeTemplate()
+	diff, err := resource.Diff(ctx, nil, terraform.NewResourceConfigRaw(map[string]any{
+		FieldClusterID:        clusterId,
+		FieldNodeTemplateName: "large",
+		FieldNodeTemplateConstraints: []any{
+			map[string]any{"min_cpu": 64},
+		},
+	}), provider)
+	r.NoError(err)
+	r.Equal("2", diff.Attributes[FieldNodeTemplateMatchedInstanceTypes+".#"].New)
+	r.Equal("c5.18xlarge", diff.Attributes[FieldNodeTemplateMatchedInstanceTypes+".0"].New)
+	r.Equal("m5.24xlarge", diff.Attributes[FieldNodeTemplateMatchedInstanceTypes+".1"].New)
+}
+
+func TestNodeTemplateResourceMatchedInstanceTypesDiffNoMatches(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil
+		}).AnyTimes()
+
+	resource := resourceNodeTemplate()
+	diff, err := resource.Diff(ctx, nil, terraform.NewResourceConfigRaw(map[string]any{
+		FieldClusterID:        clusterId,
+		FieldNodeTemplateName: "huge",
+		FieldNodeTemplateConstraints: []any{
+			map[string]any{"min_cpu": 1024, "spot": true},
+		},
+	}), provider)
+	r.NoError(err)
+	// Empty list known at plan time isn't part of the diff, while unknown one is planned as computed.
+	r.NotContains(diff.Attributes, FieldNodeTemplateMatchedInstanceTypes+".#")
+}
+
+func TestNodeTemplateResourceCreateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	configurationId := "7dc4f922-29c9-4377-889c-0c8c5fb8d497"
+
+	mockClient.EXPECT().
+		NodeTemplatesAPICreateNodeTemplate(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.NodeTemplatesAPICreateNodeTemplateJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal("gpu", lo.FromPtr(req.Name))
+			r.Equal(configurationId, lo.FromPtr(req.ConfigurationId))
+			r.True(lo.FromPtr(req.ShouldTaint))
+			r.Equal(map[string]string{"key-1": "value-1"}, req.CustomLabels.AdditionalProperties)
+			r.Len(lo.FromPtr(req.CustomTaints), 1)
+			r.NotNil(req.Constraints)
+			r.True(lo.FromPtr(req.Constraints.Spot))
+			r.Equal([]string{"amd64", "arm64"}, lo.FromPtr(req.Constraints.Architectures))
+			r.Equal([]string{"p4d"}, lo.FromPtr(req.Constraints.InstanceFamilies.Exclude))
+			r.Equal([]string{"NVIDIA"}, lo.FromPtr(req.Constraints.Gpu.Manufacturers))
+			r.Equal(int32(4), lo.FromPtr(req.Constraints.Gpu.MaxCount))
+
+			return testJSONResponse(http.StatusOK, `{"name": "gpu"}`), nil
+		})
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [{"template": {"name": "gpu", "version": "1", "shouldTaint": true, "customLabels": {"key-1": "value-1"}}}]}`), nil)
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil)
+
+	resource := resourceNodeTemplate()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:                   clusterId,
+		FieldNodeTemplateName:            "gpu",
+		FieldNodeTemplateConfigurationId: configurationId,
+		FieldNodeTemplateShouldTaint:     true,
+		FieldNodeTemplateCustomLabels:    map[string]any{"key-1": "value-1"},
+		FieldNodeTemplateCustomTaints: []any{
+			map[string]any{"key": "some-key", "value": "some-value"},
+		},
+		FieldNodeTemplateConstraints: []any{
+			map[string]any{
+				"spot":          true,
+				"architectures": []any{"amd64", "arm64"},
+				"instance_families": []any{
+					map[string]any{"exclude": []any{"p4d"}},
+				},
+				"gpu": []any{
+					map[string]any{"manufacturers": []any{"NVIDIA"}, "max_count": 4},
+				},
+			},
+		},
+	})
+
+	result := resource.CreateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal("gpu", data.Id())
+	r.Equal("1", data.Get(FieldNodeTemplateVersion))
+}
+
+func TestNodeTemplateResourceCreateContextCloneFrom(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	configurationId := "7dc4f922-29c9-4377-889c-0c8c5fb8d497"
+	source := `{"template": {
+		"name": "gpu",
+		"version": "4",
+		"configurationId": "7dc4f922-29c9-4377-889c-0c8c5fb8d497",
+		"shouldTaint": true,
+		"customLabels": {"team": "ml"},
+		"customTaints": [{"key": "gpu", "value": "true", "effect": "NoSchedule"}],
+		"constraints": {"spot": true, "architectures": ["amd64"], "gpu": {"manufacturers": ["NVIDIA"]}}
+	}}`
+	clone := `{"template": {
+		"name": "gpu-large",
+		"version": "1",
+		"configurationId": "7dc4f922-29c9-4377-889c-0c8c5fb8d497",
+		"shouldTaint": true,
+		"customLabels": {"team": "research"},
+		"customTaints": [{"key": "gpu", "value": "true", "effect": "NoSchedule"}],
+		"constraints": {"spot": true, "architectures": ["amd64"], "gpu": {"manufacturers": ["NVIDIA"]}}
+	}}`
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [`+source+`]}`), nil)
+	mockClient.EXPECT().
+		NodeTemplatesAPICreateNodeTemplate(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.NodeTemplatesAPICreateNodeTemplateJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal("gpu-large", lo.FromPtr(req.Name))
+			r.Equal(configurationId, lo.FromPtr(req.ConfigurationId))
+			r.True(lo.FromPtr(req.ShouldTaint))
+			r.Equal(map[string]string{"team": "research"}, req.CustomLabels.AdditionalProperties)
+			r.Equal([]sdk.NodetemplatesV1TaintWithoutEffect{{Key: lo.ToPtr("gpu"), Value: lo.ToPtr("true")}}, lo.FromPtr(req.CustomTaints))
+			r.True(lo.FromPtr(req.Constraints.Spot))
+			r.Equal([]string{"NVIDIA"}, lo.FromPtr(req.Constraints.Gpu.Manufacturers))
+
+			return testJSONResponse(http.StatusOK, `{"name": "gpu-large"}`), nil
+		})
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [`+source+`, `+clone+`]}`), nil)
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			// Instance types are matched with constraints inherited from the source template.
+			r.True(lo.FromPtr(req.Constraints.Spot))
+
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": [{"name": "p3.8xlarge"}, {"name": "g4dn.12xlarge"}]}`), nil
+		})
+
+	resource := resourceNodeTemplate()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:                clusterId,
+		FieldNodeTemplateName:         "gpu-large",
+		FieldNodeTemplateCloneFrom:    "gpu",
+		FieldNodeTemplateCustomLabels: map[string]any{"team": "research"},
+	})
+
+	result := resource.CreateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal("gpu-large", data.Id())
+	// Settings copied from the source template are not tracked, so they don't show up as changes on the next plan.
+	r.Equal(map[string]any{"team": "research"}, data.Get(FieldNodeTemplateCustomLabels))
+	r.False(data.Get(FieldNodeTemplateShouldTaint).(bool))
+	r.Empty(data.Get(FieldNodeTemplateCustomTaints))
+	r.Empty(data.Get(FieldNodeTemplateConstraints))
+	r.Equal([]any{"g4dn.12xlarge", "p3.8xlarge"}, data.Get(FieldNodeTemplateMatchedInstanceTypes))
+}
+
+func TestNodeTemplateResourceUpdateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIUpdateNodeTemplate(gomock.Any(), clusterId, "gpu", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, req sdk.NodeTemplatesAPIUpdateNodeTemplateJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.False(lo.FromPtr(req.ShouldTaint))
+			r.Equal(int32(2), lo.FromPtr(req.RebalancingConfig.MinNodes))
+			r.Equal(int32(8), lo.FromPtr(req.Constraints.MinCpu))
+
+			return testJSONResponse(http.StatusOK, `{"name": "gpu"}`), nil
+		})
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [{"template": {"name": "gpu", "version": "2", "shouldTaint": false, "customLabels": {}}}]}`), nil)
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		Return(testJSONResponse(http.StatusInternalServerError, `{"message": "internal error"}`), nil)
+
+	resource := resourceNodeTemplate()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:                             clusterId,
+		FieldNodeTemplateName:                      "gpu",
+		FieldNodeTemplateShouldTaint:               false,
+		FieldNodeTemplateRebalancingConfigMinNodes: 2,
+		FieldNodeTemplateConstraints: []any{
+			map[string]any{"min_cpu": 8},
+		},
+	})
+	data.SetId("gpu")
+
+	// Failure to match instance types doesn't fail the update.
+	result := resource.UpdateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal("2", data.Get(FieldNodeTemplateVersion))
+}
+
+func TestNodeTemplateResourceUpdateContextCustomTaintsWithoutShouldTaint(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	resource := resourceNodeTemplate()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:               "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		FieldNodeTemplateName:        "gpu",
+		FieldNodeTemplateShouldTaint: false,
+		FieldNodeTemplateCustomTaints: []any{
+			map[string]any{"key": "dedicated", "value": "gpu"},
+		},
+	})
+	data.SetId("gpu")
+
+	result := resource.UpdateContext(context.Background(), data, provider)
+	r.True(result.HasError())
+	r.Equal("shouldTaint must be true for the node template to have custom taints", result[0].Summary)
+}
+
+func TestNodeTemplateValidateTaints(t *testing.T) {
+	taints := &[]sdk.NodetemplatesV1TaintWithoutEffect{{Key: lo.ToPtr("dedicated")}}
+
+	tests := map[string]struct {
+		shouldTaint  *bool
+		customTaints *[]sdk.NodetemplatesV1TaintWithoutEffect
+		wantErr      bool
+	}{
+		"nothing set": {},
+		"should taint not set with custom taints": {
+			customTaints: taints,
+		},
+		"should taint set without custom taints": {
+			shouldTaint: lo.ToPtr(true),
+		},
+		"should taint disabled without custom taints": {
+			shouldTaint: lo.ToPtr(false),
+		},
+		"should taint disabled with cleared custom taints": {
+			shouldTaint:  lo.ToPtr(false),
+			customTaints: &[]sdk.NodetemplatesV1TaintWithoutEffect{},
+		},
+		"should taint enabled with custom taints": {
+			shouldTaint:  lo.ToPtr(true),
+			customTaints: taints,
+		},
+		"should taint disabled with custom taints": {
+			shouldTaint:  lo.ToPtr(false),
+			customTaints: taints,
+			wantErr:      true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			err := validateTaints(tt.shouldTaint, tt.customTaints)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNodeTemplateResourceUpdateContextClearsRemovedFields(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIUpdateNodeTemplate(gomock.Any(), clusterId, "gpu", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, body sdk.NodeTemplatesAPIUpdateNodeTemplateJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			got, err := json.Marshal(body)
+			r.NoError(err)
+
+			req := map[string]any{}
+			r.NoError(json.Unmarshal(got, &req))
+			r.Equal(map[string]any{}, req["customLabels"])
+			r.Equal([]any{}, req["customTaints"])
+			r.Equal(map[string]any{
+				"computeOptimized":           nil,
+				"fallbackRestoreRateSeconds": nil,
+				"maxCpu":                     nil,
+				"maxMemory":                  nil,
+				"minCpu":                     nil,
+				"minMemory":                  nil,
+				"spot":                       nil,
+				"storageOptimized":           nil,
+				"useSpotFallbacks":           nil,
+			}, req["constraints"])
+
+			return testJSONResponse(http.StatusOK, `{"name": "gpu"}`), nil
+		})
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [{"template": {"name": "gpu", "shouldTaint": true, "customLabels": {}}}]}`), nil)
+
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil
+		}).AnyTimes()
+
+	resource := resourceNodeTemplate()
+	state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+		FieldClusterID:               cty.StringVal(clusterId),
+		FieldNodeTemplateName:        cty.StringVal("gpu"),
+		FieldNodeTemplateShouldTaint: cty.True,
+		FieldNodeTemplateCustomLabels: cty.MapVal(map[string]cty.Value{
+			"key-1": cty.StringVal("value-1"),
+		}),
+		FieldNodeTemplateCustomTaints: cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"key":    cty.StringVal("some-key"),
+				"value":  cty.StringVal("some-value"),
+				"effect": cty.StringVal("NoSchedule"),
+			}),
+		}),
+		FieldNodeTemplateConstraints: cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"spot":    cty.True,
+				"min_cpu": cty.NumberIntVal(4),
+			}),
+		}),
+	}), 0)
+	state.ID = "gpu"
+
+	config := terraform.NewResourceConfigRaw(map[string]any{
+		FieldClusterID:               clusterId,
+		FieldNodeTemplateName:        "gpu",
+		FieldNodeTemplateShouldTaint: true,
+	})
+	diff, err := resource.Diff(ctx, state, config, provider)
+	r.NoError(err)
+
+	data, err := schema.InternalMap(resource.Schema).Data(state, diff)
+	r.NoError(err)
+
+	result := resource.UpdateContext(ctx, data, provider)
+	r.Nil(result)
+}
+
+func TestNodeTemplateResourceDeleteContext(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	tests := map[string]struct {
+		stats       string
+		forceDelete bool
+		expectedErr string
+	}{
+		"template without nodes": {
+			stats: `{"countOnDemand": 0, "countSpot": 0, "countFallback": 0}`,
+		},
+		"template with active nodes": {
+			stats:       `{"countOnDemand": 1, "countSpot": 2, "countFallback": 0}`,
+			expectedErr: `node template "gpu" still has 3 active nodes (1 on-demand, 2 spot, 0 fallback), deleting it leaves them without a template`,
+		},
+		"forced delete of template with active nodes": {
+			stats:       `{"countOnDemand": 1, "countSpot": 2, "countFallback": 0}`,
+			forceDelete: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+			if !tt.forceDelete {
+				mockClient.EXPECT().
+					NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+					Return(testJSONResponse(http.StatusOK, `{"items": [{"stats": `+tt.stats+`, "template": {"name": "gpu"}}]}`), nil)
+			}
+			if tt.expectedErr == "" {
+				mockClient.EXPECT().
+					NodeTemplatesAPIDeleteNodeTemplate(gomock.Any(), clusterId, "gpu").
+					Return(testJSONResponse(http.StatusOK, `{}`), nil)
+			}
+
+			resource := resourceNodeTemplate()
+			data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+				FieldClusterID:               clusterId,
+				FieldNodeTemplateName:        "gpu",
+				FieldNodeTemplateForceDelete: tt.forceDelete,
+			})
+			data.SetId("gpu")
+
+			result := resource.DeleteContext(context.Background(), data, provider)
+			if tt.expectedErr == "" {
+				r.Nil(result)
+				return
+			}
+			r.True(result.HasError())
+			r.Contains(result[0].Summary, tt.expectedErr)
+		})
+	}
+}
+
+func TestNodeTemplateResourceImport(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		DoAndReturn(func(_ context.Context, _ string, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			return testJSONResponse(http.StatusOK, `{"items": [{"template": {"name": "default"}}, {"template": {"name": "gpu"}}]}`), nil
+		}).Times(2)
+
+	resource := resourceNodeTemplate()
+
+	data := resource.Data(&terraform.InstanceState{ID: clusterId + "/gpu"})
+	result, err := resource.Importer.StateContext(ctx, data, provider)
+	r.NoError(err)
+	r.Len(result, 1)
+	r.Equal("gpu", result[0].Id())
+	r.Equal(clusterId, result[0].Get(FieldClusterID))
+
+	data = resource.Data(&terraform.InstanceState{ID: clusterId + "/missing"})
+	_, err = resource.Importer.StateContext(ctx, data, provider)
+	r.EqualError(err, "failed to find node template with the following name: missing")
+
+	data = resource.Data(&terraform.InstanceState{ID: "gpu"})
+	_, err = resource.Importer.StateContext(ctx, data, provider)
+	r.Error(err)
+}
+
+func TestToTemplateConstraintsMinValues(t *testing.T) {
+	r := require.New(t)
+
+	obj := map[string]any{
+		"min_cpu":    0,
+		"max_cpu":    0,
+		"min_memory": 0,
+		"max_memory": 0,
+	}
+
+	// Unset min values are sent as null, so the API clears them.
+	config := cty.ObjectVal(map[string]cty.Value{
+		"min_cpu":    cty.NullVal(cty.Number),
+		"min_memory": cty.NullVal(cty.Number),
+	})
+	constraints := toTemplateConstraints(withConfiguredMinValues(obj, config))
+	r.Nil(constraints.MinCpu)
+	r.Nil(constraints.MaxCpu)
+	r.Nil(constraints.MinMemory)
+	r.Nil(constraints.MaxMemory)
+
+	body, err := json.Marshal(constraints)
+	r.NoError(err)
+	r.Contains(string(body), `"minCpu":null`)
+	r.Contains(string(body), `"minMemory":null`)
+
+	// Explicit 0 is sent as a constraint.
+	config = cty.ObjectVal(map[string]cty.Value{
+		"min_cpu":    cty.NumberIntVal(0),
+		"min_memory": cty.NumberIntVal(0),
+	})
+	constraints = toTemplateConstraints(withConfiguredMinValues(obj, config))
+	r.Equal(int32(0), lo.FromPtr(constraints.MinCpu))
+	r.NotNil(constraints.MinCpu)
+	r.NotNil(constraints.MinMemory)
+	r.Nil(constraints.MaxCpu)
+	r.Contains(obj, "min_cpu", "configuration must not be modified")
+
+	// Without configuration, zero is treated as unset.
+	constraints = toTemplateConstraints(withConfiguredMinValues(obj, cty.NilVal))
+	r.Nil(constraints.MinCpu)
+	r.Nil(constraints.MinMemory)
+
+	constraints = toTemplateConstraints(withConfiguredMinValues(map[string]any{
+		"min_cpu":    2,
+		"min_memory": 2048,
+	}, cty.NilVal))
+	r.Equal(int32(2), lo.FromPtr(constraints.MinCpu))
+	r.Equal(int32(2048), lo.FromPtr(constraints.MinMemory))
+}
+
 func TestNodeTemplateResourceReadContextEmptyList(t *testing.T) {
 	r := require.New(t)
 	mockctrl := gomock.NewController(t)
@@ -185,7 +1069,7 @@ func TestNodeTemplateResourceReadContextEmptyList(t *testing.T) {
 
 	resource := resourceNodeTemplate()
 	val := cty.ObjectVal(map[string]cty.Value{
-		FieldClusterId:        cty.StringVal(clusterId),
+		FieldClusterID:        cty.StringVal(clusterId),
 		FieldNodeTemplateName: cty.StringVal("gpu"),
 	})
 	state := terraform.NewInstanceStateShimmedFromValue(val, 0)
@@ -193,9 +1077,9 @@ func TestNodeTemplateResourceReadContextEmptyList(t *testing.T) {
 
 	data := resource.Data(state)
 	result := resource.ReadContext(ctx, data, provider)
-	r.NotNil(result)
-	r.True(result.HasError())
-	r.Equal(result[0].Summary, "failed to find node template with name: gpu")
+	r.Nil(result)
+	r.False(result.HasError())
+	r.Empty(data.Id())
 }
 
 func TestAccResourceNodeTemplate_basic(t *testing.T) {
@@ -223,8 +1107,8 @@ func TestAccResourceNodeTemplate_basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "custom_taints.0.value", "custom-taint-value-1"),
 					resource.TestCheckResourceAttr(resourceName, "custom_taints.1.key", "custom-taint-key-2"),
 					resource.TestCheckResourceAttr(resourceName, "custom_taints.1.value", "custom-taint-value-2"),
-					resource.TestCheckResourceAttr(resourceName, "constraints.0.instance_families.0.exclude.0", "m5"),
-					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.manufacturers.0", "NVIDIA"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.instance_families.0.exclude.*", "m5"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.gpu.0.manufacturers.*", "NVIDIA"),
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.include_names.#", "0"),
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.exclude_names.#", "0"),
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.min_cpu", "4"),
@@ -232,7 +1116,7 @@ func TestAccResourceNodeTemplate_basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.use_spot_fallbacks", "true"),
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.spot", "true"),
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.architectures.#", "1"),
-					resource.TestCheckResourceAttr(resourceName, "constraints.0.architectures.0", "amd64"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.architectures.*", "amd64"),
 				),
 			},
 			{
@@ -268,7 +1152,7 @@ func TestAccResourceNodeTemplate_basic(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.use_spot_fallbacks", "true"),
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.spot", "true"),
 					resource.TestCheckResourceAttr(resourceName, "constraints.0.architectures.#", "1"),
-					resource.TestCheckResourceAttr(resourceName, "constraints.0.architectures.0", "arm64"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.architectures.*", "arm64"),
 				),
 			},
 		},
@@ -281,6 +1165,179 @@ func TestAccResourceNodeTemplate_basic(t *testing.T) {
 	})
 }
 
+// TestAccResourceNodeTemplate_fakeAPI runs against the fake API, so it doesn't need CAST AI or cloud credentials.
+func TestAccResourceNodeTemplate_fakeAPI(t *testing.T) {
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	rName := fmt.Sprintf("%v-node-template-%v", ResourcePrefix, acctest.RandString(8))
+	resourceName := "castai_node_template.test"
+	api := newFakeAPI(t, clusterID)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProviderFactories: fakeAPIProviderFactories(api),
+		CheckDestroy:      api.checkDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNodeTemplateFakeAPIConfig(rName, clusterID, `["m5"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttrPair(resourceName, "configuration_id", "castai_node_configuration.test", "id"),
+					resource.TestCheckResourceAttr(resourceName, "custom_labels.%", "1"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.spot", "true"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.instance_families.0.include.*", "m5"),
+					resource.TestCheckResourceAttr("castai_node_configuration.test", "subnets.#", "2"),
+					resource.TestCheckResourceAttr("castai_node_configuration.test", "eks.0.security_groups.#", "1"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportStateId:     fmt.Sprintf("%v/%v", clusterID, rName),
+				ImportState:       true,
+				ImportStateVerify: true,
+				// Matched instance types are only resolved on apply.
+				ImportStateVerifyIgnore: []string{FieldNodeTemplateMatchedInstanceTypes},
+			},
+			{
+				Config: testAccNodeTemplateFakeAPIConfig(rName, clusterID, `["m5", "c5"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.instance_families.0.include.#", "2"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.instance_families.0.include.*", "c5"),
+				),
+			},
+		},
+	})
+}
+
+func testAccNodeTemplateFakeAPIConfig(rName, clusterID, families string) string {
+	return fmt.Sprintf(`
+provider "castai" {
+  api_token = "fake"
+}
+
+resource "castai_node_configuration" "test" {
+  name       = %[1]q
+  cluster_id = %[2]q
+  subnets    = ["subnet-1", "subnet-2"]
+  eks {
+    instance_profile_arn = "arn:aws:iam::000000000000:instance-profile/test"
+    security_groups      = ["sg-1"]
+  }
+}
+
+resource "castai_node_template" "test" {
+  cluster_id       = %[2]q
+  name             = %[1]q
+  configuration_id = castai_node_configuration.test.id
+  custom_labels = {
+    team = "fake"
+  }
+  constraints {
+    spot = true
+    instance_families {
+      include = %[3]s
+    }
+  }
+}
+`, rName, clusterID, families)
+}
+
+func TestAccResourceNodeTemplate_allConstraints(t *testing.T) {
+	rName := fmt.Sprintf("%v-node-template-%v", ResourcePrefix, acctest.RandString(8))
+	resourceName := "castai_node_template.test"
+	clusterName := "cost-terraform"
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckNodeTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNodeTemplateAllConstraintsConfig(rName, clusterName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "name", rName),
+					resource.TestCheckResourceAttr(resourceName, "constraints.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.spot", "true"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.use_spot_fallbacks", "true"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.fallback_restore_rate_seconds", "1800"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.min_cpu", "4"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.max_cpu", "64"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.min_memory", "4096"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.max_memory", "262144"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.storage_optimized", "false"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.compute_optimized", "false"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.instance_families.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.instance_families.0.include.#", "3"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.instance_families.0.include.*", "g4dn"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.instance_families.0.include.*", "g5"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.instance_families.0.include.*", "g5g"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.instance_families.0.exclude.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.manufacturers.#", "1"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.gpu.0.manufacturers.*", "NVIDIA"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.include_names.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.exclude_names.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.exclude_names.0", "K80"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.min_count", "1"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.gpu.0.max_count", "4"),
+					resource.TestCheckResourceAttr(resourceName, "constraints.0.architectures.#", "2"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.architectures.*", "amd64"),
+					resource.TestCheckTypeSetElemAttr(resourceName, "constraints.0.architectures.*", "arm64"),
+				),
+			},
+			{
+				ResourceName: resourceName,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					clusterID := s.RootModule().Resources["castai_eks_cluster.test"].Primary.ID
+					return fmt.Sprintf("%v/%v", clusterID, rName), nil
+				},
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+		ExternalProviders: map[string]resource.ExternalProvider{
+			"aws": {
+				Source:            "hashicorp/aws",
+				VersionConstraint: "~> 4.0",
+			},
+		},
+	})
+}
+
+func testAccNodeTemplateAllConstraintsConfig(rName, clusterName string) string {
+	return ConfigCompose(testAccEKSClusterConfig(rName, clusterName), testAccNodeConfig(rName), fmt.Sprintf(`
+		resource "castai_node_template" "test" {
+			cluster_id        = castai_eks_clusterid.test.id
+			name = %[1]q
+			configuration_id = castai_node_configuration.test.id
+			should_taint = true
+
+			constraints {
+				spot = true
+				use_spot_fallbacks = true
+				fallback_restore_rate_seconds = 1800
+				min_cpu = 4
+				max_cpu = 64
+				min_memory = 4096
+				max_memory = 262144
+				storage_optimized = false
+				compute_optimized = false
+				architectures = ["amd64", "arm64"]
+
+				instance_families {
+					include = ["g4dn", "g5", "g5g"]
+				}
+
+				gpu {
+					manufacturers = ["NVIDIA"]
+					include_names = []
+					exclude_names = ["K80"]
+					min_count = 1
+					max_count = 4
+				}
+			}
+		}
+	`, rName))
+}
+
 func testAccNodeTemplateConfig(rName, clusterName string) string {
 	return ConfigCompose(testAccEKSClusterConfig(rName, clusterName), testAccNodeConfig(rName), fmt.Sprintf(`
 		resource "castai_node_template" "test" {
@@ -375,14 +1432,17 @@ func testAccCheckNodeTemplateDestroy(s *terraform.State) error {
 			return err
 		}
 		if response.StatusCode() == http.StatusNotFound {
-			return nil
+			continue
 		}
-		if len(*response.JSON200.Items) == 0 {
-			// Should be no templates
-			return nil
+		if response.JSON200 == nil || response.JSON200.Items == nil {
+			continue
 		}
 
-		return fmt.Errorf("node template %q still exists", id)
+		for _, item := range *response.JSON200.Items {
+			if item.Template != nil && lo.FromPtr(item.Template.Name) == id {
+				return fmt.Errorf("node template %q still exists", id)
+			}
+		}
 	}
 
 	return nil
@@ -407,3 +1467,209 @@ resource "castai_node_configuration" "test" {
 }
 `, rName))
 }
+
+func TestNodeTemplateResourceStateUpgradeV0(t *testing.T) {
+	r := require.New(t)
+
+	state := map[string]any{
+		FieldClusterID:        "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		FieldNodeTemplateName: "gpu",
+		FieldNodeTemplateConstraints: []any{
+			map[string]any{"spot": true, "min_memory": 2048},
+		},
+	}
+
+	upgraded, err := nodeTemplateStateUpgradeV0(context.Background(), state, nil)
+	r.NoError(err)
+	r.Equal([]any{
+		map[string]any{
+			"spot":                              true,
+			"min_memory":                        2048,
+			"normalize_memory":                  false,
+			"skip_instance_families_validation": false,
+		},
+	}, upgraded[FieldNodeTemplateConstraints])
+	r.Equal("gpu", upgraded[FieldNodeTemplateName])
+}
+
+func TestNodeTemplateResourceCreateContextMergesCustomLabel(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	mockClient.EXPECT().
+		NodeTemplatesAPICreateNodeTemplate(gomock.Any(), clusterId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.NodeTemplatesAPICreateNodeTemplateJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			// Deprecated custom label is only sent as part of custom labels.
+			r.Nil(req.CustomLabel)
+			r.Equal(map[string]string{"team": "ml", "gpu": "true"}, req.CustomLabels.AdditionalProperties)
+
+			return testJSONResponse(http.StatusOK, `{"name": "gpu"}`), nil
+		})
+	mockClient.EXPECT().
+		NodeTemplatesAPIListNodeTemplates(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"items": [{"template": {"name": "gpu", "customLabels": {"team": "ml", "gpu": "true"}}}]}`), nil)
+	mockClient.EXPECT().
+		NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil)
+
+	resource := resourceNodeTemplate()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:                clusterId,
+		FieldNodeTemplateName:         "gpu",
+		FieldNodeTemplateCustomLabel:  []any{map[string]any{"key": "gpu", "value": "true"}},
+		FieldNodeTemplateCustomLabels: map[string]any{"team": "ml", "gpu": "false"},
+	})
+
+	result := resource.CreateContext(ctx, data, provider)
+	r.False(result.HasError())
+	r.Len(result, 1)
+	r.Equal(diag.Warning, result[0].Severity)
+	r.Equal("`custom_label` is deprecated, use `custom_labels` instead", result[0].Summary)
+	r.Contains(result[0].Detail, `Replace `+"`custom_label` and `custom_labels`"+` of the node template with:
+
+  custom_labels = {
+    "gpu"  = "true"
+    "team" = "ml"
+  }`)
+	// Label of the deprecated custom label stays in its own attribute, so it matches the configuration.
+	r.Equal([]any{map[string]any{"key": "gpu", "value": "true"}}, data.Get(FieldNodeTemplateCustomLabel))
+	r.Equal(map[string]any{"team": "ml"}, data.Get(FieldNodeTemplateCustomLabels))
+}
+
+func TestNodeTemplateResourceStateUpgradeV1(t *testing.T) {
+	tests := map[string]struct {
+		state    map[string]any
+		expected map[string]any
+	}{
+		"custom label is moved to custom labels": {
+			state: map[string]any{
+				FieldNodeTemplateCustomLabel:  []any{map[string]any{"key": "gpu", "value": "true"}},
+				FieldNodeTemplateCustomLabels: map[string]any{"team": "ml"},
+			},
+			expected: map[string]any{
+				FieldNodeTemplateCustomLabel:  []any{},
+				FieldNodeTemplateCustomLabels: map[string]any{"team": "ml", "gpu": "true"},
+			},
+		},
+		"custom labels are created": {
+			state: map[string]any{
+				FieldNodeTemplateCustomLabel: []any{map[string]any{"key": "gpu", "value": "true"}},
+			},
+			expected: map[string]any{
+				FieldNodeTemplateCustomLabel:  []any{},
+				FieldNodeTemplateCustomLabels: map[string]any{"gpu": "true"},
+			},
+		},
+		"no custom label": {
+			state: map[string]any{
+				FieldNodeTemplateCustomLabel:  []any{},
+				FieldNodeTemplateCustomLabels: map[string]any{"team": "ml"},
+			},
+			expected: map[string]any{
+				FieldNodeTemplateCustomLabel:  []any{},
+				FieldNodeTemplateCustomLabels: map[string]any{"team": "ml"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+
+			upgraded, err := nodeTemplateStateUpgradeV1(context.Background(), tt.state, nil)
+			r.NoError(err)
+			r.Equal(tt.expected, upgraded)
+		})
+	}
+}
+
+func TestNodeTemplateResourceStateUpgradeV2(t *testing.T) {
+	r := require.New(t)
+
+	state := map[string]any{
+		FieldNodeTemplateConstraints: []any{map[string]any{
+			"architectures":     []any{"amd64", "arm64", "amd64"},
+			"instance_families": []any{map[string]any{"include": []any{"c5", "M5", "m5"}, "exclude": []any{}}},
+			"gpu":               []any{map[string]any{"manufacturers": []any{"NVIDIA", "NVIDIA"}}},
+		}},
+	}
+
+	upgraded, err := nodeTemplateStateUpgradeV2(context.Background(), state, nil)
+	r.NoError(err)
+	r.Equal(map[string]any{
+		FieldNodeTemplateConstraints: []any{map[string]any{
+			"architectures":     []any{"amd64", "arm64"},
+			"instance_families": []any{map[string]any{"include": []any{"c5", "M5"}, "exclude": []any{}}},
+			"gpu":               []any{map[string]any{"manufacturers": []any{"NVIDIA"}}},
+		}},
+	}, upgraded)
+}
+
+func TestNodeTemplateResourceEquivalentNamesDiffSuppressed(t *testing.T) {
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+
+	tests := map[string]struct {
+		include      []any
+		expectChange bool
+	}{
+		"different case and order": {
+			include: []any{"C5", "m5"},
+		},
+		"different families": {
+			include:      []any{"m5", "c6"},
+			expectChange: true,
+		},
+		"added family": {
+			include:      []any{"m5", "c5", "r5"},
+			expectChange: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+			provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+			mockClient.EXPECT().
+				NodeTemplatesAPIFilterInstanceTypes(gomock.Any(), clusterId, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ sdk.NodeTemplatesAPIFilterInstanceTypesJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+					return testJSONResponse(http.StatusOK, `{"availableInstanceTypes": []}`), nil
+				}).AnyTimes()
+
+			resource := resourceNodeTemplate()
+			state := terraform.NewInstanceStateShimmedFromValue(cty.ObjectVal(map[string]cty.Value{
+				FieldClusterID:        cty.StringVal(clusterId),
+				FieldNodeTemplateName: cty.StringVal("gpu"),
+				FieldNodeTemplateConstraints: cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+					"instance_families": cty.ListVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+						"include": cty.SetVal([]cty.Value{cty.StringVal("m5"), cty.StringVal("c5")}),
+					})}),
+				})}),
+			}), 0)
+			state.ID = "gpu"
+
+			diff, err := resource.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]any{
+				FieldClusterID:        clusterId,
+				FieldNodeTemplateName: "gpu",
+				FieldNodeTemplateConstraints: []any{map[string]any{
+					"instance_families": []any{map[string]any{"include": tt.include}},
+				}},
+			}), provider)
+			r.NoError(err)
+
+			var changed bool
+			if diff != nil {
+				for k := range diff.Attributes {
+					changed = changed || strings.HasPrefix(k, "constraints.0.instance_families.")
+				}
+			}
+			r.Equal(tt.expectChange, changed)
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	FieldNodeTemplatesNodeTemplate = "node_template"
+)
+
+func resourceNodeTemplates() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNodeTemplatesCreate,
+		ReadContext:   resourceNodeTemplatesRead,
+		UpdateContext: resourceNodeTemplatesUpdate,
+		DeleteContext: resourceNodeTemplatesDelete,
+		CustomizeDiff: nodeTemplatesDiff,
+		Description: "CAST AI node templates resource manages multiple node templates of a cluster at once. Templates are " +
+			"read with a single API call and only changed templates are written, which makes plans of clusters with many " +
+			"similar templates much faster than with a `castai_node_template` resource per template. Templates are " +
+			"identified by name, renaming a template replaces it.",
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldClusterID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id.",
+			},
+			FieldNodeTemplatesNodeTemplate: {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						FieldNodeTemplateName: {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+							Description:      "Name of the node template, it must be unique in the cluster.",
+						},
+						FieldNodeTemplateConfigurationId: {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+							Description:      "CAST AI node configuration id to be used for node template.",
+						},
+						FieldNodeTemplateShouldTaint: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Marks whether the templated nodes will have a taint.",
+						},
+						FieldNodeTemplateConstraints: nodeTemplateConstraintsSchema(),
+						FieldNodeTemplateCustomLabels: {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Description: "Custom labels to be added to nodes created from this template.",
+						},
+						FieldNodeTemplateCustomTaints: nodeTemplateCustomTaintsSchema(),
+						FieldNodeTemplateCustomInstancesEnabled: {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+							Description: "Marks whether custom instances should be used when deciding which parts of inventory are available. " +
+								"Custom instances are only supported in GCP.",
+						},
+					},
+				},
+				Description: "Node templates of the cluster. Use a `dynamic` block to define templates from a map.",
+			},
+		},
+	}
+}
+
+// nodeTemplatesDiff rejects duplicate template names and memory constraints which would be rejected by the API.
+func nodeTemplatesDiff(_ context.Context, d *schema.ResourceDiff, _ any) error {
+	seen := map[string]bool{}
+	for i, v := range d.Get(FieldNodeTemplatesNodeTemplate).([]any) {
+		t, _ := v.(map[string]any)
+		if name, _ := t[FieldNodeTemplateName].(string); name != "" {
+			if seen[name] {
+				return fmt.Errorf("node template %q is defined more than once", name)
+			}
+			seen[name] = true
+		}
+
+		path := fmt.Sprintf("%s.%d.%s", FieldNodeTemplatesNodeTemplate, i, FieldNodeTemplateConstraints)
+		if err := validateMemoryConstraints(t[FieldNodeTemplateConstraints], path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceNodeTemplatesCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	clusterID := d.Get(FieldClusterID).(string)
+	d.SetId(clusterID)
+
+	var created []string
+	for _, t := range nodeTemplateDefinitions(d.Get(FieldNodeTemplatesNodeTemplate)) {
+		if err := createNodeTemplateDefinition(ctx, meta, clusterID, t); err != nil {
+			if len(created) == 0 {
+				d.SetId("")
+				return diag.FromErr(err)
+			}
+			// Templates created so far are kept in state, so they aren't orphaned.
+			return append(readNodeTemplates(ctx, d, meta, created), diag.FromErr(err)...)
+		}
+		created = append(created, t[FieldNodeTemplateName].(string))
+	}
+
+	return readNodeTemplates(ctx, d, meta, created)
+}
+
+func resourceNodeTemplatesRead(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	return readNodeTemplates(ctx, d, meta, nodeTemplateNames(d.Get(FieldNodeTemplatesNodeTemplate)))
+}
+
+func resourceNodeTemplatesUpdate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	if !d.HasChange(FieldNodeTemplatesNodeTemplate) {
+		log.Printf("[INFO] Nothing to update in node templates")
+		return nil
+	}
+
+	clusterID := d.Get(FieldClusterID).(string)
+	o, n := d.GetChange(FieldNodeTemplatesNodeTemplate)
+	oldTemplates := lo.KeyBy(nodeTemplateDefinitions(o), func(t map[string]any) string { return t[FieldNodeTemplateName].(string) })
+	newTemplates := nodeTemplateDefinitions(n)
+
+	// Templates which exist in the cluster, so that state matches the cluster when some of the changes fail.
+	existing := lo.Uniq(append(nodeTemplateNames(n), nodeTemplateNames(o)...))
+	present := lo.SliceToMap(nodeTemplateNames(o), func(name string) (string, bool) { return name, true })
+	failed := func(err error) diag.Diagnostics {
+		names := lo.Filter(existing, func(name string, _ int) bool { return present[name] })
+		return append(readNodeTemplates(ctx, d, meta, names), diag.FromErr(err)...)
+	}
+
+	// Removed templates are deleted first, so the cluster doesn't have more templates than configured in between.
+	for name := range oldTemplates {
+		if lo.ContainsBy(newTemplates, func(t map[string]any) bool { return t[FieldNodeTemplateName] == name }) {
+			continue
+		}
+		if err := deleteNodeTemplateDefinition(ctx, meta, clusterID, name); err != nil {
+			return failed(err)
+		}
+		delete(present, name)
+	}
+
+	for _, t := range newTemplates {
+		name := t[FieldNodeTemplateName].(string)
+		old, ok := oldTemplates[name]
+		switch {
+		case !ok:
+			if err := createNodeTemplateDefinition(ctx, meta, clusterID, t); err != nil {
+				return failed(err)
+			}
+			present[name] = true
+		case !reflect.DeepEqual(toNodeTemplateDefinitionUpdate(old), toNodeTemplateDefinitionUpdate(t)):
+			if err := updateNodeTemplateDefinition(ctx, meta, clusterID, t); err != nil {
+				return failed(err)
+			}
+		}
+	}
+
+	return readNodeTemplates(ctx, d, meta, nodeTemplateNames(n))
+}
+
+func resourceNodeTemplatesDelete(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
+	clusterID := d.Get(FieldClusterID).(string)
+
+	names := nodeTemplateNames(d.Get(FieldNodeTemplatesNodeTemplate))
+	for i, name := range names {
+		if err := deleteNodeTemplateDefinition(ctx, meta, clusterID, name); err != nil {
+			return append(readNodeTemplates(ctx, d, meta, names[i:]), diag.FromErr(err)...)
+		}
+	}
+
+	return nil
+}
+
+// readNodeTemplates sets templates with the names, in the given order, from a single list of cluster templates.
+// Templates which no longer exist are dropped, so they are planned for creation.
+func readNodeTemplates(ctx context.Context, d *schema.ResourceData, meta any, names []string) diag.Diagnostics {
+	clusterID := d.Get(FieldClusterID).(string)
+
+	resp, err := listNodeTemplates(ctx, meta, clusterID)
+	if removeFromStateIfNotFound(d, "Node templates", err == nil && isNotFound(resp)) {
+		return nil
+	}
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(fmt.Errorf("listing node templates: %w", checkErr))
+	}
+
+	templates := map[string]sdk.NodetemplatesV1NodeTemplate{}
+	for _, item := range lo.FromPtr(resp.JSON200.Items) {
+		if item.Template != nil {
+			templates[lo.FromPtr(item.Template.Name)] = *item.Template
+		}
+	}
+	configured := lo.KeyBy(nodeTemplateDefinitions(d.Get(FieldNodeTemplatesNodeTemplate)), func(t map[string]any) string {
+		return t[FieldNodeTemplateName].(string)
+	})
+
+	out := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		t, ok := templates[name]
+		if !ok {
+			log.Printf("[WARN] Node template %q of cluster %q not found, it will be created", name, clusterID)
+			continue
+		}
+		flat, err := flattenNodeTemplateDefinition(t, configured[name])
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("flattening node template %q: %w", name, err))
+		}
+		out = append(out, flat)
+	}
+
+	if err := d.Set(FieldNodeTemplatesNodeTemplate, out); err != nil {
+		return diag.FromErr(fmt.Errorf("setting node templates: %w", err))
+	}
+
+	return nil
+}
+
+func createNodeTemplateDefinition(ctx context.Context, meta any, clusterID string, t map[string]any) error {
+	client := meta.(*ProviderConfig).api
+	name := t[FieldNodeTemplateName].(string)
+
+	update := toNodeTemplateDefinitionUpdate(t)
+	if err := validateTaints(update.ShouldTaint, update.CustomTaints); err != nil {
+		return fmt.Errorf("node template %q: %w", name, err)
+	}
+	req := sdk.NodeTemplatesAPICreateNodeTemplateJSONRequestBody{
+		Name:                   lo.ToPtr(name),
+		ConfigurationId:        update.ConfigurationId,
+		Constraints:            update.Constraints,
+		CustomInstancesEnabled: update.CustomInstancesEnabled,
+		CustomLabels:           &sdk.NodetemplatesV1NewNodeTemplate_CustomLabels{AdditionalProperties: update.CustomLabels.AdditionalProperties},
+		CustomTaints:           update.CustomTaints,
+		ShouldTaint:            update.ShouldTaint,
+	}
+
+	log.Printf("[INFO] Creating node template %q", name)
+	resp, err := client.NodeTemplatesAPICreateNodeTemplateWithResponse(ctx, clusterID, req)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeTemplates, clusterID))
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return fmt.Errorf("creating node template %q: %w", name, checkErr)
+	}
+
+	return nil
+}
+
+func updateNodeTemplateDefinition(ctx context.Context, meta any, clusterID string, t map[string]any) error {
+	client := meta.(*ProviderConfig).api
+	name := t[FieldNodeTemplateName].(string)
+
+	req := toNodeTemplateDefinitionUpdate(t)
+	if err := validateTaints(req.ShouldTaint, req.CustomTaints); err != nil {
+		return fmt.Errorf("node template %q: %w", name, err)
+	}
+
+	log.Printf("[INFO] Updating node template %q", name)
+	resp, err := client.NodeTemplatesAPIUpdateNodeTemplateWithResponse(ctx, clusterID, name, req)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeTemplates, clusterID))
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return fmt.Errorf("updating node template %q: %w", name, checkErr)
+	}
+
+	return nil
+}
+
+func deleteNodeTemplateDefinition(ctx context.Context, meta any, clusterID, name string) error {
+	client := meta.(*ProviderConfig).api
+
+	log.Printf("[INFO] Deleting node template %q", name)
+	resp, err := client.NodeTemplatesAPIDeleteNodeTemplateWithResponse(ctx, clusterID, name)
+	meta.(*ProviderConfig).responses.invalidate(listCacheKey(listCacheEndpointNodeTemplates, clusterID))
+	if err == nil && isNotFound(resp) {
+		return nil
+	}
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return fmt.Errorf("deleting node template %q: %w", name, checkErr)
+	}
+
+	return nil
+}
+
+// toNodeTemplateDefinitionUpdate returns the template in API representation. All settings are sent, so settings removed
+// from the configuration are cleared instead of keeping their previous values.
+func toNodeTemplateDefinitionUpdate(t map[string]any) sdk.NodeTemplatesAPIUpdateNodeTemplateJSONRequestBody {
+	req := sdk.NodeTemplatesAPIUpdateNodeTemplateJSONRequestBody{
+		ShouldTaint:            lo.ToPtr(t[FieldNodeTemplateShouldTaint].(bool)),
+		CustomInstancesEnabled: lo.ToPtr(t[FieldNodeTemplateCustomInstancesEnabled].(bool)),
+		CustomLabels:           &sdk.NodetemplatesV1UpdateNodeTemplate_CustomLabels{AdditionalProperties: toStringMap(t[FieldNodeTemplateCustomLabels].(map[string]any))},
+		CustomTaints:           &[]sdk.NodetemplatesV1TaintWithoutEffect{},
+		Constraints:            &sdk.NodetemplatesV1TemplateConstraints{},
+	}
+	if v, _ := t[FieldNodeTemplateConfigurationId].(string); v != "" {
+		req.ConfigurationId = lo.ToPtr(v)
+	}
+	if v, ok := t[FieldNodeTemplateConstraints].([]any); ok && len(v) > 0 && v[0] != nil {
+		// Templates are compared with the state, which doesn't tell an explicit 0 from an unset value, so zero min
+		// values are sent as null.
+		req.Constraints = toTemplateConstraints(withConfiguredMinValues(v[0].(map[string]any), cty.NilVal))
+	}
+	if v, ok := t[FieldNodeTemplateCustomTaints].([]any); ok && len(v) > 0 {
+		req.CustomTaints = toCustomTaintsWithoutEffect(lo.Map(v, func(taint any, _ int) map[string]any {
+			return taint.(map[string]any)
+		}))
+	}
+
+	return req
+}
+
+// flattenNodeTemplateDefinition returns the template in state representation. Settings which only affect the provider
+// aren't returned by the API, so they are kept from the configured template.
+func flattenNodeTemplateDefinition(t sdk.NodetemplatesV1NodeTemplate, configured map[string]any) (map[string]any, error) {
+	out := map[string]any{
+		FieldNodeTemplateName:                   lo.FromPtr(t.Name),
+		FieldNodeTemplateConfigurationId:        lo.FromPtr(t.ConfigurationId),
+		FieldNodeTemplateShouldTaint:            lo.FromPtr(t.ShouldTaint),
+		FieldNodeTemplateCustomLabels:           withCustomLabel(lo.FromPtr(t.CustomLabels).AdditionalProperties, t.CustomLabel),
+		FieldNodeTemplateCustomTaints:           flattenCustomTaints(t.CustomTaints),
+		FieldNodeTemplateCustomInstancesEnabled: lo.FromPtr(t.CustomInstancesEnabled),
+	}
+
+	if t.Constraints != nil {
+		constraints, err := flattenConstraints(t.Constraints)
+		if err != nil {
+			return nil, err
+		}
+		var prev map[string]any
+		if v, ok := configured[FieldNodeTemplateConstraints].([]any); ok && len(v) > 0 && v[0] != nil {
+			prev = v[0].(map[string]any)
+		}
+		constraints[0]["normalize_memory"], _ = prev["normalize_memory"].(bool)
+		constraints[0]["skip_instance_families_validation"], _ = prev["skip_instance_families_validation"].(bool)
+		out[FieldNodeTemplateConstraints] = constraints
+	}
+
+	return out, nil
+}
+
+func nodeTemplateDefinitions(v any) []map[string]any {
+	list, _ := v.([]any)
+	return lo.FilterMap(list, func(t any, _ int) (map[string]any, bool) {
+		m, ok := t.(map[string]any)
+		return m, ok
+	})
+}
+
+func nodeTemplateNames(v any) []string {
+	return lo.Map(nodeTemplateDefinitions(v), func(t map[string]any, _ int) string {
+		return t[FieldNodeTemplateName].(string)
+	})
+}
@@ -0,0 +1,126 @@
+package castai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeTemplatesResourceLifecycle(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	api := newFakeAPI(t, clusterID)
+
+	provider, err := fakeAPIProviderFactories(api)[ProviderName]()
+	r.NoError(err)
+	meta, diags := provider.ConfigureContextFunc(ctx, nil)
+	r.Nil(diags)
+
+	resource := resourceNodeTemplates()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID: clusterID,
+		FieldNodeTemplatesNodeTemplate: []any{
+			map[string]any{
+				FieldNodeTemplateName:         "spot",
+				FieldNodeTemplateCustomLabels: map[string]any{"pool": "spot"},
+				FieldNodeTemplateConstraints:  []any{map[string]any{"spot": true, "min_memory": 2000, "normalize_memory": true}},
+			},
+			map[string]any{
+				FieldNodeTemplateName:        "tainted",
+				FieldNodeTemplateShouldTaint: true,
+			},
+		},
+	})
+
+	r.Nil(resource.CreateContext(ctx, data, meta))
+	r.Equal(clusterID, data.Id())
+	r.Len(api.templates, 2)
+	r.Equal("spot", data.Get("node_template.0.name"))
+	r.Equal("spot", data.Get("node_template.0.custom_labels.pool"))
+	r.Equal(true, data.Get("node_template.0.constraints.0.spot"))
+	r.Equal(2048, data.Get("node_template.0.constraints.0.min_memory"))
+	r.Equal(true, data.Get("node_template.0.constraints.0.normalize_memory"))
+	r.Equal("tainted", data.Get("node_template.1.name"))
+	r.Equal(true, data.Get("node_template.1.should_taint"))
+
+	// "spot" is removed, "tainted" is changed and "gpu" is added.
+	state := data.State()
+	diff, err := resource.Diff(ctx, state, terraform.NewResourceConfigRaw(map[string]any{
+		FieldClusterID: clusterID,
+		FieldNodeTemplatesNodeTemplate: []any{
+			map[string]any{
+				FieldNodeTemplateName:        "gpu",
+				FieldNodeTemplateConstraints: []any{map[string]any{"gpu": []any{map[string]any{"min_count": 1}}}},
+			},
+			map[string]any{
+				FieldNodeTemplateName:        "tainted",
+				FieldNodeTemplateShouldTaint: true,
+				FieldNodeTemplateCustomTaints: []any{
+					map[string]any{"key": "dedicated", "value": "tainted"},
+				},
+			},
+		},
+	}), meta)
+	r.NoError(err)
+	data, err = schema.InternalMap(resource.Schema).Data(state, diff)
+	r.NoError(err)
+
+	r.Nil(resource.UpdateContext(ctx, data, meta))
+	r.Len(api.templates, 2)
+	r.NotContains(api.templates, "spot")
+	r.Equal("gpu", data.Get("node_template.0.name"))
+	r.Equal(1, data.Get("node_template.0.constraints.0.gpu.0.min_count"))
+	r.Equal("tainted", data.Get("node_template.1.name"))
+	r.Equal("dedicated", data.Get("node_template.1.custom_taints.0.key"))
+
+	r.Nil(resource.DeleteContext(ctx, data, meta))
+	r.NoError(api.checkDestroyed(nil))
+}
+
+func TestNodeTemplatesResourceReadContext(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	api := newFakeAPI(t, clusterID)
+	api.templates["b"] = map[string]any{"name": "b", "shouldTaint": true, "customInstancesEnabled": false}
+	api.templates["unmanaged"] = map[string]any{"name": "unmanaged"}
+
+	provider, err := fakeAPIProviderFactories(api)[ProviderName]()
+	r.NoError(err)
+	meta, diags := provider.ConfigureContextFunc(ctx, nil)
+	r.Nil(diags)
+
+	resource := resourceNodeTemplates()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID: clusterID,
+		FieldNodeTemplatesNodeTemplate: []any{
+			map[string]any{FieldNodeTemplateName: "a"},
+			map[string]any{FieldNodeTemplateName: "b"},
+		},
+	})
+	data.SetId(clusterID)
+
+	// Missing templates are dropped, so they are created again. Templates which aren't managed are ignored.
+	r.Nil(resource.ReadContext(ctx, data, meta))
+	r.Equal(1, data.Get("node_template.#"))
+	r.Equal("b", data.Get("node_template.0.name"))
+	r.Equal(true, data.Get("node_template.0.should_taint"))
+}
+
+func TestNodeTemplatesResourceDiffRejectsDuplicateNames(t *testing.T) {
+	r := require.New(t)
+
+	resource := resourceNodeTemplates()
+	_, err := resource.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]any{
+		FieldClusterID: "b6bfc074-a267-400f-b8f1-db0850c369b1",
+		FieldNodeTemplatesNodeTemplate: []any{
+			map[string]any{FieldNodeTemplateName: "a"},
+			map[string]any{FieldNodeTemplateName: "a"},
+		},
+	}), nil)
+	r.ErrorContains(err, `node template "a" is defined more than once`)
+}
@@ -0,0 +1,209 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestNodeResourceCreateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	nodeID := "4e4cd9eb-a9ab-4a9c-8f2c-5d7e3b1c2a10"
+	mockClient.EXPECT().
+		ExternalClusterAPIAddNode(gomock.Any(), clusterID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req sdk.ExternalClusterAPIAddNodeJSONRequestBody, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal("m5.large", req.InstanceType)
+			r.Equal("eu-central-1a", lo.FromPtr(req.Zone))
+			r.True(lo.FromPtr(req.SpotConfig.IsSpot))
+			r.Equal(map[string]string{"pool": "static"}, req.KubernetesLabels.AdditionalProperties)
+			r.Equal([]sdk.ExternalclusterV1Taint{{Key: "static", Value: "true", Effect: taintEffectNoSchedule}}, lo.FromPtr(req.KubernetesTaints))
+			r.Nil(req.Volume)
+
+			return testJSONResponse(http.StatusOK, `{"nodeId": "`+nodeID+`", "operationId": "add"}`), nil
+		})
+	gomock.InOrder(
+		mockClient.EXPECT().
+			GetExternalClusterOperation(gomock.Any(), "add").
+			Return(testJSONResponse(http.StatusOK, `{"id": "add", "done": false}`), nil),
+		mockClient.EXPECT().
+			GetExternalClusterOperation(gomock.Any(), "add").
+			Return(testJSONResponse(http.StatusOK, `{"id": "add", "done": true}`), nil),
+	)
+	mockClient.EXPECT().
+		ExternalClusterAPIGetNode(gomock.Any(), clusterID, nodeID).
+		Return(testJSONResponse(http.StatusOK, `{
+			"id": "`+nodeID+`",
+			"name": "ip-10-0-1-5",
+			"instanceType": "m5.large",
+			"instanceId": "i-0123456789",
+			"zone": "eu-central-1a",
+			"nodeConfigurationId": "7dc4f922-29c9-4377-889c-0c8c5fb8d497",
+			"spotConfig": {"isSpot": true},
+			"network": {"privateIp": "10.0.1.5"}
+		}`), nil)
+
+	resource := resourceNode()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:            clusterID,
+		FieldNodeInstanceType:     "m5.large",
+		FieldNodeZone:             "eu-central-1a",
+		FieldNodeSpot:             true,
+		FieldNodeKubernetesLabels: map[string]any{"pool": "static"},
+		FieldNodeKubernetesTaints: []any{map[string]any{"key": "static", "value": "true"}},
+	})
+
+	r.Nil(resource.CreateContext(context.Background(), data, provider))
+	r.Equal(nodeID, data.Id())
+	r.Equal("ip-10-0-1-5", data.Get(FieldNodeName))
+	r.Equal("i-0123456789", data.Get(FieldNodeInstanceID))
+	r.Equal("10.0.1.5", data.Get(FieldNodePrivateIP))
+	r.Equal("7dc4f922-29c9-4377-889c-0c8c5fb8d497", data.Get(FieldNodeConfigurationID))
+}
+
+func TestNodeResourceCreateContextOperationError(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		ExternalClusterAPIAddNode(gomock.Any(), clusterID, gomock.Any()).
+		Return(testJSONResponse(http.StatusOK, `{"nodeId": "node", "operationId": "add"}`), nil)
+	mockClient.EXPECT().
+		GetExternalClusterOperation(gomock.Any(), "add").
+		Return(testJSONResponse(http.StatusOK, `{"id": "add", "done": true, "error": {"reason": "capacity", "details": "no capacity"}}`), nil)
+
+	resource := resourceNode()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:        clusterID,
+		FieldNodeInstanceType: "m5.large",
+	})
+
+	result := resource.CreateContext(context.Background(), data, provider)
+	r.True(result.HasError())
+	r.Equal("adding node node: operation add failed: capacity: no capacity", result[0].Summary)
+	// Node is kept in state, so it's deleted before it's added again.
+	r.Equal("node", data.Id())
+}
+
+func TestNodeResourceReadContextNotFound(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		ExternalClusterAPIGetNode(gomock.Any(), clusterID, "node").
+		Return(testJSONResponse(http.StatusNotFound, `{"message": "node not found"}`), nil)
+
+	resource := resourceNode()
+	data := resource.Data(&terraform.InstanceState{ID: "node", Attributes: map[string]string{FieldClusterID: clusterID}})
+
+	r.Nil(resource.ReadContext(context.Background(), data, provider))
+	r.Empty(data.Id())
+}
+
+func TestNodeResourceDeleteContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		ExternalClusterAPIDeleteNode(gomock.Any(), clusterID, "node", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, params *sdk.ExternalClusterAPIDeleteNodeParams, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			r.Equal("120", lo.FromPtr(params.DrainTimeout))
+			r.True(lo.FromPtr(params.ForceDelete))
+
+			return testJSONResponse(http.StatusOK, `{"operationId": "delete"}`), nil
+		})
+	mockClient.EXPECT().
+		GetExternalClusterOperation(gomock.Any(), "delete").
+		Return(testJSONResponse(http.StatusOK, `{"id": "delete", "done": true}`), nil)
+
+	resource := resourceNode()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]any{
+		FieldClusterID:               clusterID,
+		FieldNodeInstanceType:        "m5.large",
+		FieldNodeDrainTimeoutSeconds: 120,
+		FieldNodeForceDelete:         true,
+	})
+	data.SetId("node")
+
+	r.Nil(resource.DeleteContext(context.Background(), data, provider))
+}
+
+// TestAccResourceNode_fakeAPI runs against the fake API, so it doesn't need CAST AI or cloud credentials.
+func TestAccResourceNode_fakeAPI(t *testing.T) {
+	clusterID := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	resourceName := "castai_node.test"
+	api := newFakeAPI(t, clusterID)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProviderFactories: fakeAPIProviderFactories(api),
+		CheckDestroy:      api.checkDestroyed,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNodeFakeAPIConfig(clusterID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "instance_type", "m5.large"),
+					resource.TestCheckResourceAttr(resourceName, "zone", "eu-central-1b"),
+					resource.TestCheckResourceAttr(resourceName, "spot", "true"),
+					resource.TestCheckResourceAttrSet(resourceName, "name"),
+					resource.TestCheckResourceAttrSet(resourceName, "private_ip"),
+				),
+			},
+			{
+				ResourceName: resourceName,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return fmt.Sprintf("%v/%v", clusterID, s.RootModule().Resources[resourceName].Primary.ID), nil
+				},
+				ImportState:       true,
+				ImportStateVerify: true,
+				// Settings which CAST AI doesn't return are not imported, see the import notes of the resource.
+				ImportStateVerifyIgnore: []string{FieldNodeSubnetID, FieldNodeVolumeSize, FieldNodeKubernetesLabels, FieldNodeKubernetesTaints},
+			},
+		},
+	})
+}
+
+func testAccNodeFakeAPIConfig(clusterID string) string {
+	return fmt.Sprintf(`
+provider "castai" {
+  api_token = "fake"
+}
+
+resource "castai_node" "test" {
+  cluster_id    = %[1]q
+  instance_type = "m5.large"
+  zone          = "eu-central-1b"
+  subnet_id     = "subnet-1"
+  spot          = true
+  volume_size   = 100
+
+  kubernetes_labels = {
+    pool = "static"
+  }
+
+  kubernetes_taints {
+    key   = "static"
+    value = "true"
+  }
+}
+`, clusterID)
+}
@@ -0,0 +1,184 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	FieldROSAClusterName        = "name"
+	FieldROSAClusterRegion      = "region"
+	FieldROSAClusterInternalID  = "internal_id"
+	FieldROSAClusterCredentials = "credentials_json"
+
+	// ROSA clusters are always running on AWS.
+	rosaClusterCloud = "aws"
+)
+
+func resourceROSACluster() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCastaiROSAClusterCreate,
+		ReadContext:   resourceCastaiROSAClusterRead,
+		UpdateContext: resourceCastaiROSAClusterUpdate,
+		DeleteContext: resourceCastaiClusterDelete,
+		CustomizeDiff: customdiff.All(clusterTokenDiff, clusterModeDiff(FieldROSAClusterCredentials)),
+		Description:   "ROSA cluster resource allows connecting an existing Red Hat OpenShift Service on AWS cluster to CAST AI.",
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+			Delete: schema.DefaultTimeout(6 * time.Minute), // Cluster action timeout is 5 minutes.
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldROSAClusterName: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "OpenShift cluster name",
+			},
+			FieldROSAClusterRegion: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "AWS region of the cluster",
+			},
+			FieldROSAClusterInternalID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+				Description:      "OpenShift cluster id, can be found in `spec.clusterID` of the `version` ClusterVersion object",
+			},
+			FieldROSAClusterCredentials: {
+				Type:             schema.TypeString,
+				Sensitive:        true,
+				Optional:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
+				Description:      "AWS credentials in JSON format used by CAST AI to manage the cluster",
+			},
+			FieldClusterCredentialsId: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "CAST AI credentials id for cluster",
+			},
+			FieldClusterToken: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "CAST.AI agent cluster token",
+			},
+			FieldDeleteNodesOnDisconnect: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Should CAST AI remove nodes managed by CAST.AI on disconnect",
+			},
+			FieldClusterMode:             clusterModeSchema(FieldROSAClusterCredentials),
+			FieldClusterResumeOnboarding: clusterResumeOnboardingSchema(FieldROSAClusterCredentials),
+		},
+	}
+}
+
+func resourceCastaiROSAClusterCreate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	req := sdk.ExternalClusterAPIRegisterClusterJSONRequestBody{
+		Name: data.Get(FieldROSAClusterName).(string),
+		Openshift: &sdk.ExternalclusterV1OpenshiftClusterParams{
+			ClusterName: toPtr(data.Get(FieldROSAClusterName).(string)),
+			Cloud:       toPtr(rosaClusterCloud),
+			Region:      toPtr(data.Get(FieldROSAClusterRegion).(string)),
+			InternalId:  toPtr(data.Get(FieldROSAClusterInternalID).(string)),
+		},
+	}
+
+	log.Printf("[INFO] Registering new external cluster: %#v", req)
+
+	resp, err := client.ExternalClusterAPIRegisterClusterWithResponse(ctx, req)
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return diag.FromErr(checkErr)
+	}
+
+	// Cluster id is kept in state from now on, so a failure of later phases doesn't leave the cluster registered twice.
+	data.SetId(*resp.JSON200.Id)
+	tkn, err := createClusterToken(ctx, client, data.Id())
+	if err != nil {
+		return clusterTokenFailed(data.Id(), err)
+	}
+	if err := data.Set(FieldClusterToken, tkn); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster token: %w", err))
+	}
+
+	if err := updateClusterCredentials(ctx, data, client, FieldROSAClusterCredentials); err != nil {
+		diags := clusterCredentialsFailed(data, FieldROSAClusterCredentials, err)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceCastaiROSAClusterRead(ctx, data, meta)...)
+	}
+	log.Printf("[INFO] Cluster with id %q has been registered, don't forget to install castai-agent helm chart", data.Id())
+
+	return resourceCastaiROSAClusterRead(ctx, data, meta)
+}
+
+func resourceCastaiROSAClusterRead(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	if data.Id() == "" {
+		log.Printf("[INFO] id is null not fetching anything.")
+		return nil
+	}
+
+	log.Printf("[INFO] Getting cluster information.")
+
+	resp, err := fetchClusterData(ctx, client, data.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if resp == nil {
+		data.SetId("")
+		return nil
+	}
+
+	if err := data.Set(FieldClusterCredentialsId, toString(resp.JSON200.CredentialsId)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting credentials id: %w", err))
+	}
+	if err := data.Set(FieldClusterMode, clusterMode(toString(resp.JSON200.CredentialsId))); err != nil {
+		return diag.FromErr(fmt.Errorf("setting mode: %w", err))
+	}
+	if openshift := resp.JSON200.Openshift; openshift != nil {
+		if err := data.Set(FieldROSAClusterName, toString(openshift.ClusterName)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting cluster name: %w", err))
+		}
+		if err := data.Set(FieldROSAClusterRegion, toString(openshift.Region)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting region: %w", err))
+		}
+		if err := data.Set(FieldROSAClusterInternalID, toString(openshift.InternalId)); err != nil {
+			return diag.FromErr(fmt.Errorf("setting internal id: %w", err))
+		}
+	}
+
+	return nil
+}
+
+func resourceCastaiROSAClusterUpdate(ctx context.Context, data *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	if err := updateClusterCredentials(ctx, data, client, FieldROSAClusterCredentials); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceCastaiROSAClusterRead(ctx, data, meta)
+}
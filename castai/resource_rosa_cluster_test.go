@@ -0,0 +1,77 @@
+package castai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestROSAClusterResourceReadContext(t *testing.T) {
+	r := require.New(t)
+	mockctrl := gomock.NewController(t)
+	mockClient := mock_sdk.NewMockClientInterface(mockctrl)
+
+	ctx := context.Background()
+	provider := &ProviderConfig{
+		api: &sdk.ClientWithResponses{
+			ClientInterface: mockClient,
+		},
+	}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c3rosa"
+
+	body := io.NopCloser(bytes.NewReader([]byte(`{
+  "id": "b6bfc074-a267-400f-b8f1-db0850c3rosa",
+  "name": "rosa-cluster",
+  "organizationId": "2836f775-aaaa-eeee-bbbb-3d3c2951rosa",
+  "credentialsId": "9b8d0456-177b-4a3d-b162-e68030d6rosa",
+  "createdAt": "2022-04-27T19:03:31.570829Z",
+  "region": {
+    "name": "eu-central-1",
+    "displayName": "EU (Frankfurt)"
+  },
+  "status": "ready",
+  "agentSnapshotReceivedAt": "2022-05-21T10:33:56.192020Z",
+  "agentStatus": "online",
+  "providerType": "openshift",
+  "openshift": {
+    "clusterName": "rosa-cluster",
+    "cloud": "aws",
+    "region": "eu-central-1",
+    "internalId": "f2b5d2b8-5cd4-4b6e-9f0a-1b2c3d4e5f60"
+  },
+  "clusterNameId": "rosa-cluster-b6bfc074"
+}`)))
+	mockClient.EXPECT().
+		ExternalClusterAPIGetCluster(gomock.Any(), clusterId).
+		Return(&http.Response{StatusCode: 200, Body: body, Header: map[string][]string{"Content-Type": {"json"}}}, nil)
+
+	resource := resourceROSACluster()
+
+	val := cty.ObjectVal(map[string]cty.Value{})
+	state := terraform.NewInstanceStateShimmedFromValue(val, 0)
+	state.ID = clusterId
+
+	data := resource.Data(state)
+	result := resource.ReadContext(ctx, data, provider)
+	r.Nil(result)
+	r.False(result.HasError())
+	r.Equal(`ID = b6bfc074-a267-400f-b8f1-db0850c3rosa
+credentials_id = 9b8d0456-177b-4a3d-b162-e68030d6rosa
+internal_id = f2b5d2b8-5cd4-4b6e-9f0a-1b2c3d4e5f60
+mode = full-access
+name = rosa-cluster
+region = eu-central-1
+Tainted = false
+`, data.State().String())
+}
@@ -0,0 +1,189 @@
+package castai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+const (
+	FieldScalingPolicyEnabled  = "enabled"
+	FieldScalingPolicyMinCores = "min_cores"
+	FieldScalingPolicyMaxCores = "max_cores"
+)
+
+func resourceScalingPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScalingPolicyCreate,
+		ReadContext:   resourceScalingPolicyRead,
+		UpdateContext: resourceScalingPolicyUpdate,
+		DeleteContext: resourceScalingPolicyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: scalingPolicyStateImporter,
+		},
+		Description: "CAST AI scaling policy resource to manage cluster CPU limits independently from `castai_autoscaler`",
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
+			Read:   schema.DefaultTimeout(1 * time.Minute),
+			Update: schema.DefaultTimeout(1 * time.Minute),
+			Delete: schema.DefaultTimeout(1 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldClusterID: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsUUID),
+				Description:      "CAST AI cluster id",
+			},
+			FieldScalingPolicyEnabled: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Enable/disable cluster size limits policy",
+			},
+			FieldScalingPolicyMinCores: {
+				Type:             schema.TypeInt,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+				Description:      "Minimum allowed amount of vCPUs in the whole cluster",
+			},
+			FieldScalingPolicyMaxCores: {
+				Type:             schema.TypeInt,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Maximum allowed amount of vCPUs in the whole cluster",
+			},
+		},
+		CustomizeDiff: func(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+			minCores, maxCores := d.Get(FieldScalingPolicyMinCores).(int), d.Get(FieldScalingPolicyMaxCores).(int)
+			if minCores > maxCores {
+				return fmt.Errorf("%s (%d) can't be greater than %s (%d)", FieldScalingPolicyMinCores, minCores, FieldScalingPolicyMaxCores, maxCores)
+			}
+			return nil
+		},
+	}
+}
+
+func resourceScalingPolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clusterID := d.Get(FieldClusterID).(string)
+	if err := upsertClusterLimits(ctx, meta, clusterID, toClusterLimitsPolicy(d)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(clusterID)
+
+	return resourceScalingPolicyRead(ctx, d, meta)
+}
+
+func resourceScalingPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*ProviderConfig).api
+
+	current, err := getCurrentPolicies(ctx, client, d.Id())
+	if removeFromStateIfNotFound(d, "Scaling policy", isNotFoundErr(err)) {
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var policies sdk.PoliciesV1Policies
+	if err := json.Unmarshal(current, &policies); err != nil {
+		return diag.FromErr(fmt.Errorf("parsing policies: %w", err))
+	}
+
+	limits := lo.FromPtr(policies.ClusterLimits)
+	cpu := lo.FromPtr(limits.Cpu)
+
+	if err := d.Set(FieldClusterID, d.Id()); err != nil {
+		return diag.FromErr(fmt.Errorf("setting cluster id: %w", err))
+	}
+	if err := d.Set(FieldScalingPolicyEnabled, lo.FromPtr(limits.Enabled)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting enabled: %w", err))
+	}
+	if err := d.Set(FieldScalingPolicyMinCores, lo.FromPtr(cpu.MinCores)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting min cores: %w", err))
+	}
+	if err := d.Set(FieldScalingPolicyMaxCores, lo.FromPtr(cpu.MaxCores)); err != nil {
+		return diag.FromErr(fmt.Errorf("setting max cores: %w", err))
+	}
+
+	return nil
+}
+
+func resourceScalingPolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.HasChanges(FieldScalingPolicyEnabled, FieldScalingPolicyMinCores, FieldScalingPolicyMaxCores) {
+		log.Printf("[INFO] Nothing to update in scaling policy")
+		return nil
+	}
+
+	if err := upsertClusterLimits(ctx, meta, d.Id(), toClusterLimitsPolicy(d)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceScalingPolicyRead(ctx, d, meta)
+}
+
+func resourceScalingPolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Limits are kept as they are, only the policy is disabled.
+	if err := upsertClusterLimits(ctx, meta, d.Id(), sdk.PoliciesV1ClusterLimitsPolicy{Enabled: lo.ToPtr(false)}); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func toClusterLimitsPolicy(d *schema.ResourceData) sdk.PoliciesV1ClusterLimitsPolicy {
+	return sdk.PoliciesV1ClusterLimitsPolicy{
+		Enabled: lo.ToPtr(d.Get(FieldScalingPolicyEnabled).(bool)),
+		Cpu: &sdk.PoliciesV1ClusterLimitsCpu{
+			MinCores: lo.ToPtr(int32(d.Get(FieldScalingPolicyMinCores).(int))),
+			MaxCores: lo.ToPtr(int32(d.Get(FieldScalingPolicyMaxCores).(int))),
+		},
+	}
+}
+
+// upsertClusterLimits merges cluster limits into current cluster policies, so other policies stay intact.
+func upsertClusterLimits(ctx context.Context, meta interface{}, clusterID string, limits sdk.PoliciesV1ClusterLimitsPolicy) error {
+	client := meta.(*ProviderConfig).api
+
+	clusterLocks.Lock(clusterID)
+	defer clusterLocks.Unlock(clusterID)
+
+	current, err := getCurrentPolicies(ctx, client, clusterID)
+	if err != nil {
+		return err
+	}
+
+	changes, err := json.Marshal(map[string]interface{}{"clusterLimits": limits})
+	if err != nil {
+		return fmt.Errorf("marshaling cluster limits: %w", err)
+	}
+
+	policies, err := jsonpatch.MergePatch(current, changes)
+	if err != nil {
+		return fmt.Errorf("failed to merge policies: %w", err)
+	}
+
+	return upsertPolicies(ctx, meta, clusterID, string(policies))
+}
+
+func scalingPolicyStateImporter(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	if err := d.Set(FieldClusterID, d.Id()); err != nil {
+		return nil, fmt.Errorf("setting cluster id: %w", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
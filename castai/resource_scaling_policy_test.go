@@ -0,0 +1,123 @@
+package castai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+	mock_sdk "github.com/castai/terraform-provider-castai/castai/sdk/mock"
+)
+
+func TestScalingPolicyResourceCreateContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	ctx := context.Background()
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	currentPolicies := `{
+		"enabled": true,
+		"isScopedMode": false,
+		"clusterLimits": {"enabled": false, "cpu": {"minCores": 1, "maxCores": 20}},
+		"nodeDownscaler": {"emptyNodes": {"enabled": true, "delaySeconds": 60}}
+	}`
+	updatedPolicies := `{
+		"enabled": true,
+		"isScopedMode": false,
+		"clusterLimits": {"enabled": true, "cpu": {"minCores": 4, "maxCores": 100}},
+		"nodeDownscaler": {"emptyNodes": {"enabled": true, "delaySeconds": 60}}
+	}`
+
+	gomock.InOrder(
+		mockClient.EXPECT().
+			PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, currentPolicies), nil),
+		mockClient.EXPECT().
+			PoliciesAPIUpsertClusterPoliciesWithBody(gomock.Any(), clusterId, "application/json", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, body io.Reader, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+				got, _ := io.ReadAll(body)
+				eq, err := JSONBytesEqual(got, []byte(updatedPolicies))
+				r.NoError(err)
+				r.True(eq, string(got))
+
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(updatedPolicies)))}, nil
+			}),
+		mockClient.EXPECT().
+			PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+			Return(testJSONResponse(http.StatusOK, updatedPolicies), nil),
+	)
+
+	resource := resourceScalingPolicy()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID:             clusterId,
+		FieldScalingPolicyMinCores: 4,
+		FieldScalingPolicyMaxCores: 100,
+	})
+
+	result := resource.CreateContext(ctx, data, provider)
+	r.Nil(result)
+	r.Equal(clusterId, data.Id())
+	r.Equal(true, data.Get(FieldScalingPolicyEnabled))
+	r.Equal(4, data.Get(FieldScalingPolicyMinCores))
+	r.Equal(100, data.Get(FieldScalingPolicyMaxCores))
+}
+
+func TestScalingPolicyResourceDeleteContext(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusOK, `{"enabled": true, "clusterLimits": {"enabled": true, "cpu": {"minCores": 4, "maxCores": 100}}}`), nil)
+	mockClient.EXPECT().
+		PoliciesAPIUpsertClusterPoliciesWithBody(gomock.Any(), clusterId, "application/json", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, body io.Reader, _ ...sdk.RequestEditorFn) (*http.Response, error) {
+			got, _ := io.ReadAll(body)
+			eq, err := JSONBytesEqual(got, []byte(`{"enabled": true, "clusterLimits": {"enabled": false, "cpu": {"minCores": 4, "maxCores": 100}}}`))
+			r.NoError(err)
+			r.True(eq, string(got))
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(`{}`)))}, nil
+		})
+
+	resource := resourceScalingPolicy()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID:             clusterId,
+		FieldScalingPolicyMinCores: 4,
+		FieldScalingPolicyMaxCores: 100,
+	})
+	data.SetId(clusterId)
+
+	result := resource.DeleteContext(context.Background(), data, provider)
+	r.Nil(result)
+}
+
+func TestScalingPolicyResourceReadContextNotFound(t *testing.T) {
+	r := require.New(t)
+	mockClient := mock_sdk.NewMockClientInterface(gomock.NewController(t))
+	provider := &ProviderConfig{api: &sdk.ClientWithResponses{ClientInterface: mockClient}}
+
+	clusterId := "b6bfc074-a267-400f-b8f1-db0850c369b1"
+	mockClient.EXPECT().
+		PoliciesAPIGetClusterPolicies(gomock.Any(), clusterId).
+		Return(testJSONResponse(http.StatusNotFound, `{"message": "cluster not found"}`), nil)
+
+	resource := resourceScalingPolicy()
+	data := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		FieldClusterID: clusterId,
+	})
+	data.SetId(clusterId)
+
+	result := resource.ReadContext(context.Background(), data, provider)
+	r.Nil(result)
+	r.Empty(data.Id())
+}
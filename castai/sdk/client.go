@@ -2,13 +2,17 @@ package sdk
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
 )
 
+// DefaultHTTPTimeout is the default time limit of a single CAST AI API request, including reading the response body.
+const DefaultHTTPTimeout = 1 * time.Minute
+
 // Currently, sdk doesn't have generated constants for cluster status and agent status, declaring our own.
 const (
 	ClusterStatusReady    = "ready"
@@ -21,14 +25,82 @@ const (
 	ClusterAgentStatusDisconnecting = "disconnecting"
 )
 
-func CreateClient(apiURL, apiToken, userAgent string) (*ClientWithResponses, error) {
+type moduleNameKey struct{}
+
+// WithModuleName returns context which makes the client add Terraform module name to User-Agent of its requests.
+func WithModuleName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, moduleNameKey{}, name)
+}
+
+// transportConfig is the HTTP transport of the client created by CreateClient, before it's wrapped with logging.
+type transportConfig struct {
+	transport         *http.Transport
+	requestsPerSecond float64
+}
+
+// TransportOption customizes the HTTP transport of the client created by CreateClient.
+type TransportOption func(cfg *transportConfig) error
+
+// WithTLS makes the client trust certificates signed by CA certificates in caCertificatePEM in addition to the system
+// ones, or skip verification of API certificates altogether. It's needed behind TLS-intercepting proxies.
+func WithTLS(insecureSkipVerify bool, caCertificatePEM string) TransportOption {
+	return func(cfg *transportConfig) error {
+		cfg.transport.TLSClientConfig.InsecureSkipVerify = insecureSkipVerify
+		if caCertificatePEM == "" {
+			return nil
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(caCertificatePEM)) {
+			return fmt.Errorf("no certificates found in CA certificate PEM")
+		}
+		cfg.transport.TLSClientConfig.RootCAs = pool
+
+		return nil
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests, so large workspaces don't hit organization rate limits
+// of the API. Zero means no limit.
+func WithRateLimit(requestsPerSecond float64) TransportOption {
+	return func(cfg *transportConfig) error {
+		if requestsPerSecond < 0 {
+			return fmt.Errorf("requests per second must not be negative, got %v", requestsPerSecond)
+		}
+		cfg.requestsPerSecond = requestsPerSecond
+
+		return nil
+	}
+}
+
+func CreateClient(apiURL, apiToken, userAgent string, timeout time.Duration, transportOpts ...TransportOption) (*ClientWithResponses, error) {
+	cfg := &transportConfig{transport: newHTTPTransport(timeout)}
+	for _, opt := range transportOpts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	transport := newLoggingTransport(cfg.transport)
+	if cfg.requestsPerSecond > 0 {
+		// Waiting for the limiter isn't logged as request duration.
+		transport = newRateLimitedTransport(transport, cfg.requestsPerSecond)
+	}
+
 	httpClientOption := func(client *Client) error {
 		client.Client = &http.Client{
-			Transport: logging.NewSubsystemLoggingHTTPTransport("CAST.AI", http.DefaultTransport),
-			Timeout:   1 * time.Minute,
+			Transport: transport,
+			Timeout:   timeout,
 		}
-		client.RequestEditors = append(client.RequestEditors, func(_ context.Context, req *http.Request) error {
-			req.Header.Set("user-agent", userAgent)
+		client.RequestEditors = append(client.RequestEditors, func(ctx context.Context, req *http.Request) error {
+			ua := userAgent
+			if name, ok := ctx.Value(moduleNameKey{}).(string); ok {
+				ua += " module/" + name
+			}
+			req.Header.Set("user-agent", ua)
 			return nil
 		})
 		return nil
@@ -50,3 +122,26 @@ func CreateClient(apiURL, apiToken, userAgent string) (*ClientWithResponses, err
 
 	return apiClient, nil
 }
+
+// newHTTPTransport returns a transport which reuses connections and TLS sessions across requests. Terraform runs many
+// operations concurrently, so more idle connections are kept than by http.DefaultTransport. Each connection phase is
+// limited separately, so a slow API fails fast instead of hanging until the overall request timeout.
+func newHTTPTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		TLSClientConfig:       &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(0)},
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
@@ -0,0 +1,74 @@
+package sdk
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateClientTimeout(t *testing.T) {
+	r := require.New(t)
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := CreateClient(srv.URL, "token", "test", 100*time.Millisecond)
+	r.Error(err)
+	r.Contains(err.Error(), "validating api token")
+	r.Less(time.Since(start), 5*time.Second)
+}
+
+func TestNewHTTPTransport(t *testing.T) {
+	r := require.New(t)
+
+	transport := newHTTPTransport(30 * time.Second)
+	r.Equal(30*time.Second, transport.ResponseHeaderTimeout)
+	r.NotNil(transport.TLSClientConfig.ClientSessionCache)
+	r.Greater(transport.MaxIdleConnsPerHost, http.DefaultMaxIdleConnsPerHost)
+}
+
+func TestCreateClientTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items": []}`))
+	}))
+	defer srv.Close()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	t.Run("should fail on unknown certificate authority", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := CreateClient(srv.URL, "token", "test", 5*time.Second)
+		r.ErrorContains(err, "certificate")
+	})
+
+	t.Run("should trust configured certificate authority", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := CreateClient(srv.URL, "token", "test", 5*time.Second, WithTLS(false, caPEM))
+		r.NoError(err)
+	})
+
+	t.Run("should skip certificate verification", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := CreateClient(srv.URL, "token", "test", 5*time.Second, WithTLS(true, ""))
+		r.NoError(err)
+	})
+
+	t.Run("should fail on invalid certificate authority PEM", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := CreateClient(srv.URL, "token", "test", 5*time.Second, WithTLS(false, "invalid"))
+		r.ErrorContains(err, "no certificates found")
+	})
+}
@@ -0,0 +1,153 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	logFieldMethod     = "castai_http_method"
+	logFieldPath       = "castai_http_path"
+	logFieldStatusCode = "castai_http_status_code"
+	logFieldDurationMs = "castai_http_duration_ms"
+	logFieldError      = "castai_http_error"
+	logFieldBody       = "castai_http_body"
+
+	redactedValue = "***"
+)
+
+// sensitiveBodyKeys lists JSON keys (compared case-insensitively) which values are never written to logs.
+var sensitiveBodyKeys = map[string]struct{}{
+	"token":        {},
+	"credentials":  {},
+	"clientsecret": {},
+	"secret":       {},
+	"password":     {},
+	"apikey":       {},
+	"privatekey":   {},
+}
+
+// loggingTransport logs every CAST AI API call through tflog. Method, path, status and duration are logged on DEBUG
+// level, while sanitized request and response bodies are only logged on TRACE level. Bodies are not buffered at all
+// on other levels. Headers are never logged as they contain the API key.
+type loggingTransport struct {
+	transport http.RoundTripper
+}
+
+func newLoggingTransport(transport http.RoundTripper) http.RoundTripper {
+	return &loggingTransport{transport: transport}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	fields := map[string]interface{}{
+		logFieldMethod: req.Method,
+		logFieldPath:   req.URL.Path,
+	}
+
+	trace := traceEnabled()
+	if trace && req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		tflog.Trace(ctx, "CAST AI API request body", map[string]interface{}{
+			logFieldMethod: req.Method,
+			logFieldPath:   req.URL.Path,
+			logFieldBody:   sanitizeBody(body),
+		})
+	}
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	fields[logFieldDurationMs] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields[logFieldError] = err.Error()
+		tflog.Debug(ctx, "CAST AI API request failed", fields)
+		return resp, err
+	}
+
+	fields[logFieldStatusCode] = resp.StatusCode
+	tflog.Debug(ctx, "CAST AI API request", fields)
+
+	if trace && resp.Body != nil {
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading response body: %w", err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		tflog.Trace(ctx, "CAST AI API response body", map[string]interface{}{
+			logFieldMethod:     req.Method,
+			logFieldPath:       req.URL.Path,
+			logFieldStatusCode: resp.StatusCode,
+			logFieldBody:       sanitizeBody(body),
+		})
+	}
+
+	return resp, nil
+}
+
+// traceEnabled reports whether provider logs are written on TRACE level. Terraform passes the level to providers in
+// TF_LOG_PROVIDER, falling back to TF_LOG, where JSON also means TRACE.
+func traceEnabled() bool {
+	level := os.Getenv("TF_LOG_PROVIDER")
+	if level == "" {
+		level = os.Getenv("TF_LOG")
+	}
+
+	return strings.EqualFold(level, "TRACE") || strings.EqualFold(level, "JSON")
+}
+
+// sanitizeBody returns body suitable for logging: values of sensitive JSON keys are redacted and non JSON
+// payloads are replaced by their size, as there is no way to tell what they contain.
+func sanitizeBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Sprintf("<non-JSON body, %d bytes>", len(body))
+	}
+
+	out, err := json.Marshal(redact(v))
+	if err != nil {
+		return fmt.Sprintf("<unserializable body, %d bytes>", len(body))
+	}
+
+	return string(out)
+}
+
+func redact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if _, ok := sensitiveBodyKeys[strings.ToLower(k)]; ok {
+				val[k] = redactedValue
+				continue
+			}
+			val[k] = redact(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redact(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeBody(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		expected string
+	}{
+		"empty body": {
+			body:     "",
+			expected: "",
+		},
+		"non json body": {
+			body:     "plain text",
+			expected: "<non-JSON body, 10 bytes>",
+		},
+		"redacts sensitive keys case insensitively": {
+			body:     `{"name":"cluster","Token":"secret-token","clientSecret":"s","nested":{"password":"p","apiKey":"k"}}`,
+			expected: `{"Token":"***","clientSecret":"***","name":"cluster","nested":{"apiKey":"***","password":"***"}}`,
+		},
+		"redacts keys inside arrays": {
+			body:     `{"items":[{"id":"1","credentials":{"json":"x"}}]}`,
+			expected: `{"items":[{"credentials":"***","id":"1"}]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(tt.expected, sanitizeBody([]byte(tt.body)))
+		})
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestLoggingTransportResponseBody(t *testing.T) {
+	transport := newLoggingTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(failingReader{})}, nil
+	}))
+
+	t.Run("body read error is returned on TRACE level", func(t *testing.T) {
+		r := require.New(t)
+		t.Setenv("TF_LOG_PROVIDER", "")
+		t.Setenv("TF_LOG", "TRACE")
+
+		req := httptest.NewRequest(http.MethodGet, "https://api.cast.ai/v1/kubernetes/external-clusters", nil)
+		resp, err := transport.RoundTrip(req)
+		r.Nil(resp)
+		r.EqualError(err, "reading response body: connection reset")
+	})
+
+	t.Run("body is not read on DEBUG level", func(t *testing.T) {
+		r := require.New(t)
+		t.Setenv("TF_LOG_PROVIDER", "")
+		t.Setenv("TF_LOG", "DEBUG")
+
+		req := httptest.NewRequest(http.MethodGet, "https://api.cast.ai/v1/kubernetes/external-clusters", nil)
+		resp, err := transport.RoundTrip(req)
+		r.NoError(err)
+		_, err = io.ReadAll(resp.Body)
+		r.EqualError(err, "connection reset")
+	})
+}
@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitedTransport spreads requests evenly in time, so that at most requestsPerSecond requests are started per
+// second. Requests waiting for their turn are canceled together with their context.
+type rateLimitedTransport struct {
+	transport http.RoundTripper
+	interval  time.Duration
+	now       func() time.Time
+
+	lock sync.Mutex
+	next time.Time
+}
+
+func newRateLimitedTransport(transport http.RoundTripper, requestsPerSecond float64) *rateLimitedTransport {
+	return &rateLimitedTransport{
+		transport: transport,
+		interval:  time.Duration(float64(time.Second) / requestsPerSecond),
+		now:       time.Now,
+	}
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if delay := t.reserve(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	return t.transport.RoundTrip(req)
+}
+
+// reserve takes the next free slot and returns how long the request has to wait for it.
+func (t *rateLimitedTransport) reserve() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	start := t.next
+	if start.Before(now) {
+		start = now
+	}
+	t.next = start.Add(t.interval)
+
+	return start.Sub(now)
+}
@@ -0,0 +1,60 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitedTransportReserve(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	transport := newRateLimitedTransport(http.DefaultTransport, 4)
+	transport.now = func() time.Time { return now }
+
+	r.Equal(time.Duration(0), transport.reserve())
+	r.Equal(250*time.Millisecond, transport.reserve())
+	r.Equal(500*time.Millisecond, transport.reserve())
+
+	// Unused slots aren't saved up for bursts.
+	now = now.Add(time.Minute)
+	r.Equal(time.Duration(0), transport.reserve())
+	r.Equal(250*time.Millisecond, transport.reserve())
+}
+
+func TestRateLimitedTransportRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer srv.Close()
+
+	t.Run("should delay requests over the limit", func(t *testing.T) {
+		r := require.New(t)
+		client := &http.Client{Transport: newRateLimitedTransport(http.DefaultTransport, 20)}
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			resp, err := client.Get(srv.URL)
+			r.NoError(err)
+			r.NoError(resp.Body.Close())
+		}
+		r.GreaterOrEqual(time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("should cancel waiting request with its context", func(t *testing.T) {
+		r := require.New(t)
+		transport := newRateLimitedTransport(http.DefaultTransport, 0.01)
+		transport.reserve()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		r.NoError(err)
+
+		_, err = transport.RoundTrip(req)
+		r.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
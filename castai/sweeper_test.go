@@ -0,0 +1,201 @@
+package castai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+// Sweepers remove leftovers of failed acceptance test runs, run them with:
+//
+//	go test ./castai -v -sweep=all
+//
+// CAST AI API has no regions, so the value of -sweep flag is ignored.
+func init() {
+	resource.AddTestSweepers("castai_node_template", &resource.Sweeper{
+		Name: "castai_node_template",
+		F:    sweepNodeTemplates,
+	})
+	resource.AddTestSweepers("castai_node_configuration", &resource.Sweeper{
+		Name: "castai_node_configuration",
+		// Node configuration can't be deleted while it's referenced by a node template.
+		Dependencies: []string{"castai_node_template"},
+		F:            sweepNodeConfigurations,
+	})
+	resource.AddTestSweepers("castai_cluster", &resource.Sweeper{
+		Name:         "castai_cluster",
+		Dependencies: []string{"castai_node_template", "castai_node_configuration"},
+		F:            sweepClusters,
+	})
+}
+
+func sweeperProviderConfig() (*ProviderConfig, error) {
+	apiToken := os.Getenv("CASTAI_API_TOKEN")
+	if apiToken == "" {
+		return nil, fmt.Errorf("CASTAI_API_TOKEN must be set for sweepers")
+	}
+	apiURL := os.Getenv("CASTAI_API_URL")
+	if apiURL == "" {
+		apiURL = testAccAPIURL
+	}
+
+	client, err := sdk.CreateClient(apiURL, apiToken, "castai-terraform-provider/sweeper", sdk.DefaultHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProviderConfig{api: client, apiURL: apiURL}, nil
+}
+
+func sweepClusterIDs(ctx context.Context, client *sdk.ClientWithResponses) ([]string, error) {
+	resp, err := client.ExternalClusterAPIListClustersWithResponse(ctx, &sdk.ExternalClusterAPIListClustersParams{})
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return nil, fmt.Errorf("listing clusters: %w", checkErr)
+	}
+
+	var ids []string
+	for _, c := range lo.FromPtr(resp.JSON200.Items) {
+		status := lo.FromPtr(c.Status)
+		if status == sdk.ClusterStatusDeleted || status == sdk.ClusterStatusArchived {
+			continue
+		}
+		ids = append(ids, lo.FromPtr(c.Id))
+	}
+
+	return ids, nil
+}
+
+func sweepNodeTemplates(_ string) error {
+	ctx := context.Background()
+	meta, err := sweeperProviderConfig()
+	if err != nil {
+		return err
+	}
+	client := meta.api
+
+	clusterIDs, err := sweepClusterIDs(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var result *multierror.Error
+	for _, clusterID := range clusterIDs {
+		resp, err := client.NodeTemplatesAPIListNodeTemplatesWithResponse(ctx, clusterID)
+		if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+			log.Printf("[WARN] Skipping node templates of cluster %s: %v", clusterID, checkErr)
+			continue
+		}
+
+		for _, item := range lo.FromPtr(resp.JSON200.Items) {
+			if item.Template == nil {
+				continue
+			}
+			name := lo.FromPtr(item.Template.Name)
+			if !strings.HasPrefix(name, ResourcePrefix) {
+				continue
+			}
+
+			log.Printf("[INFO] Deleting node template %s of cluster %s", name, clusterID)
+			resp, err := client.NodeTemplatesAPIDeleteNodeTemplateWithResponse(ctx, clusterID, name)
+			if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+				result = multierror.Append(result, fmt.Errorf("deleting node template %s of cluster %s: %w", name, clusterID, checkErr))
+			}
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func sweepNodeConfigurations(_ string) error {
+	ctx := context.Background()
+	meta, err := sweeperProviderConfig()
+	if err != nil {
+		return err
+	}
+	client := meta.api
+
+	clusterIDs, err := sweepClusterIDs(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var result *multierror.Error
+	for _, clusterID := range clusterIDs {
+		resp, err := client.NodeConfigurationAPIListConfigurationsWithResponse(ctx, clusterID)
+		if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+			log.Printf("[WARN] Skipping node configurations of cluster %s: %v", clusterID, checkErr)
+			continue
+		}
+
+		for _, config := range lo.FromPtr(resp.JSON200.Items) {
+			name := lo.FromPtr(config.Name)
+			if !strings.HasPrefix(name, ResourcePrefix) {
+				continue
+			}
+			if lo.FromPtr(config.Default) {
+				// Default configuration can't be deleted, it's removed together with the cluster.
+				log.Printf("[INFO] Skipping default node configuration %s of cluster %s", name, clusterID)
+				continue
+			}
+
+			log.Printf("[INFO] Deleting node configuration %s of cluster %s", name, clusterID)
+			resp, err := client.NodeConfigurationAPIDeleteConfigurationWithResponse(ctx, clusterID, lo.FromPtr(config.Id))
+			if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+				result = multierror.Append(result, fmt.Errorf("deleting node configuration %s of cluster %s: %w", name, clusterID, checkErr))
+			}
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func sweepClusters(_ string) error {
+	ctx := context.Background()
+	meta, err := sweeperProviderConfig()
+	if err != nil {
+		return err
+	}
+
+	resp, err := meta.api.ExternalClusterAPIListClustersWithResponse(ctx, &sdk.ExternalClusterAPIListClustersParams{})
+	if checkErr := sdk.CheckOKResponse(resp, err); checkErr != nil {
+		return fmt.Errorf("listing clusters: %w", checkErr)
+	}
+
+	var result *multierror.Error
+	for _, c := range lo.FromPtr(resp.JSON200.Items) {
+		// Shared clusters of acceptance tests, e.g. core-tf-acc, may be in use by a running test, only clusters
+		// registered for a single test are swept.
+		name := lo.FromPtr(c.Name)
+		if !strings.HasPrefix(name, ResourcePrefix) {
+			continue
+		}
+		status := lo.FromPtr(c.Status)
+		if status == sdk.ClusterStatusDeleted || status == sdk.ClusterStatusArchived {
+			continue
+		}
+
+		log.Printf("[INFO] Deleting cluster %s (%s)", name, lo.FromPtr(c.Id))
+		// Cluster resources share the same delete flow, it disconnects the agent before the cluster is deleted. Nodes
+		// are kept, the same as with the default delete_nodes_on_disconnect.
+		data := resourceEKSCluster().TestResourceData()
+		data.SetId(lo.FromPtr(c.Id))
+		if diags := resourceCastaiClusterDelete(ctx, data, meta); diags.HasError() {
+			result = multierror.Append(result, fmt.Errorf("deleting cluster %s: %s", name, diags[0].Summary))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
@@ -1,6 +1,21 @@
 package castai
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/samber/lo"
+
+	"github.com/castai/terraform-provider-castai/castai/sdk"
+)
 
 func toPtr[S any](src S) *S {
 	return &src
@@ -25,6 +40,13 @@ func toStringList(items []interface{}) []string {
 	return out
 }
 
+// toSortedStringList returns values of the string set sorted, so requests don't depend on the order of set hashes.
+func toSortedStringList(set *schema.Set) []string {
+	out := toStringList(set.List())
+	sort.Strings(out)
+	return out
+}
+
 func stringToMap(s string) (map[string]interface{}, error) {
 	out := map[string]interface{}{}
 	err := json.Unmarshal([]byte(s), &out)
@@ -40,3 +62,120 @@ func toString(s *string) string {
 	}
 	return *s
 }
+
+// suppressEquivalentJSONDiffs suppresses diffs between JSON strings which differ only in formatting or key order.
+func suppressEquivalentJSONDiffs(_, old, new string, _ *schema.ResourceData) bool {
+	if old == new {
+		return true
+	}
+
+	var o, n interface{}
+	if err := json.Unmarshal([]byte(old), &o); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &n); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(o, n)
+}
+
+// notFoundError is returned by helpers fetching remote objects when the object does not exist at CAST AI.
+type notFoundError struct {
+	msg string
+}
+
+func newNotFoundError(format string, args ...any) error {
+	return &notFoundError{msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *notFoundError) Error() string {
+	return e.msg
+}
+
+func isNotFoundErr(err error) bool {
+	var nf *notFoundError
+	return errors.As(err, &nf)
+}
+
+func isNotFound(resp sdk.Response) bool {
+	return resp != nil && resp.StatusCode() == http.StatusNotFound
+}
+
+// removeFromStateIfNotFound removes the resource from state when its remote object was deleted outside of Terraform,
+// so that it is planned for re-creation instead of failing the refresh. Returns true when the resource was removed.
+// Objects missing right after creation are never ignored, callers should report them as errors.
+func removeFromStateIfNotFound(d *schema.ResourceData, kind string, notFound bool) bool {
+	if !notFound || d.IsNewResource() {
+		return false
+	}
+
+	log.Printf("[WARN] %s (%s) not found, removing from state", kind, d.Id())
+	d.SetId("")
+	return true
+}
+
+// uniqueStateValues drops duplicates from the string list of raw state. It's needed when list attributes are converted
+// to sets, since sets can't hold duplicate values.
+func uniqueStateValues(v any, ignoreCase bool) any {
+	values, ok := v.([]any)
+	if !ok {
+		return v
+	}
+
+	return lo.UniqBy(values, func(v any) string {
+		s, _ := v.(string)
+		if ignoreCase {
+			return strings.ToLower(s)
+		}
+		return s
+	})
+}
+
+// stateBlocks returns the nested blocks of raw state under the given key.
+func stateBlocks(rawState map[string]any, key string) []map[string]any {
+	values, _ := rawState[key].([]any)
+
+	var blocks []map[string]any
+	for _, v := range values {
+		if block, ok := v.(map[string]any); ok {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks
+}
+
+// configReader is implemented by both schema.ResourceData and schema.ResourceDiff.
+type configReader interface {
+	GetRawConfig() cty.Value
+	GetOk(key string) (interface{}, bool)
+}
+
+// isSetInConfig checks whether the field is set in the configuration. When configuration is not available, e.g.
+// during refresh, the current value is checked instead.
+func isSetInConfig(d configReader, key string) bool {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() || !raw.Type().IsObjectType() || !raw.Type().HasAttribute(key) {
+		_, ok := d.GetOk(key)
+		return ok
+	}
+
+	v := raw.GetAttr(key)
+	if v.IsNull() {
+		return false
+	}
+	if v.IsKnown() && v.CanIterateElements() {
+		return v.LengthInt() > 0
+	}
+
+	return true
+}
+
+// hasPlannedChanges checks whether the planned diff changes any of the keys or their nested attributes. Unlike
+// ResourceDiff.HasChanges it works for blocks with nested sets, which are never deeply equal as sets hold a hash func.
+func hasPlannedChanges(d *schema.ResourceDiff, keys ...string) bool {
+	return lo.SomeBy(keys, func(key string) bool {
+		return len(d.GetChangedKeysPrefix(key)) > 0
+	})
+}
@@ -0,0 +1,7 @@
+# Policies of IAM role and user CAST AI uses to manage the cluster.
+data "castai_eks_settings" "this" {
+  account_id = "123456789012"
+  region     = "eu-central-1"
+  vpc        = "vpc-0123456789abcdef0"
+  cluster    = "production"
+}
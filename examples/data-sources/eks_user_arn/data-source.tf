@@ -0,0 +1,4 @@
+# ARN of the IAM user CAST AI uses to access the cluster, it's trusted by the cross account role.
+data "castai_eks_user_arn" "this" {
+  cluster_id = castai_eks_clusterid.this.id
+}
@@ -0,0 +1,6 @@
+# Look up CAST AI cluster id of a GKE cluster which was already connected to CAST AI.
+data "castai_gke_cluster_id" "this" {
+  project_id   = "my-project"
+  location     = "europe-west3"
+  cluster_name = "production"
+}
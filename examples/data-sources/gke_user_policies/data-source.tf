@@ -0,0 +1,8 @@
+# Permissions of the GCP service account CAST AI uses to manage the cluster.
+data "castai_gke_user_policies" "this" {}
+
+resource "google_project_iam_custom_role" "castai" {
+  role_id     = "castai.gkeAccess"
+  title       = "Role to manage GKE cluster via CAST AI"
+  permissions = toset(data.castai_gke_user_policies.this.policy)
+}
@@ -0,0 +1,6 @@
+# GPU instance types available in the region of the cluster.
+data "castai_instance_types" "gpu" {
+  cluster_id   = castai_eks_cluster.this.id
+  architecture = "amd64"
+  gpu          = true
+}
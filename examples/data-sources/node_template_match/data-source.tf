@@ -0,0 +1,12 @@
+# Node templates a pod with the node selector and toleration can be scheduled on.
+data "castai_node_template_match" "this" {
+  cluster_id = castai_eks_cluster.this.id
+  node_selector = {
+    "scheduling.cast.ai/spot" = "true"
+  }
+
+  tolerations {
+    key      = "scheduling.cast.ai/node-template"
+    operator = "Exists"
+  }
+}
@@ -0,0 +1,11 @@
+# Spot nodes of the "gpu" node template in a single zone.
+data "castai_nodes" "gpu_spot" {
+  cluster_id    = castai_eks_cluster.this.id
+  node_template = "gpu"
+  capacity_type = "spot"
+  zone          = "eu-central-1a"
+}
+
+output "gpu_spot_instance_types" {
+  value = distinct(data.castai_nodes.gpu_spot.nodes[*].instance_type)
+}
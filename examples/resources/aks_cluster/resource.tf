@@ -10,5 +10,6 @@ resource "castai_aks_cluster" "this" {
   client_secret   = azuread_application_password.castai.value
 
   node_resource_group        = azurerm_kubernetes_cluster.this.node_resource_group
+  network_plugin             = azurerm_kubernetes_cluster.this.network_profile[0].network_plugin
   delete_nodes_on_disconnect = var.delete_nodes_on_disconnect
 }
\ No newline at end of file
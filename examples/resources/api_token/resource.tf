@@ -0,0 +1,10 @@
+# Create read only token for CI pipelines.
+resource "castai_api_token" "ci" {
+  name      = "ci-readonly"
+  read_only = true
+}
+
+output "ci_token" {
+  value     = castai_api_token.ci.token
+  sensitive = true
+}
@@ -0,0 +1,29 @@
+# Enable unschedulable pods policy with nodes of 2 to 16 CPU cores, keep 10% of spare capacity on on-demand nodes and
+# delete empty nodes after 5 minutes.
+resource "castai_autoscaler" "this" {
+  cluster_id = castai_eks_cluster.this.id
+
+  autoscaler_policies_json = jsonencode({
+    enabled = true
+    unschedulablePods = {
+      enabled = true
+    }
+    nodeDownscaler = {
+      enabled = true
+      emptyNodes = {
+        enabled      = true
+        delaySeconds = 300
+      }
+    }
+  })
+
+  headroom {
+    cpu_percentage    = 10
+    memory_percentage = 10
+  }
+
+  node_constraints {
+    min_cpu_cores = 2
+    max_cpu_cores = 16
+  }
+}
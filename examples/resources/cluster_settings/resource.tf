@@ -0,0 +1,7 @@
+# Use on-demand backups when spot instances are unavailable and delete empty nodes after 5 minutes.
+resource "castai_cluster_settings" "this" {
+  cluster_id                         = castai_eks_cluster.test.id
+  spot_backups_enabled               = true
+  empty_nodes_deletion_enabled       = true
+  empty_nodes_deletion_delay_seconds = 300
+}
@@ -0,0 +1,8 @@
+resource "castai_kops_cluster" "this" {
+  name                       = var.kops_cluster_name
+  cloud                      = "aws"
+  region                     = var.region
+  state_store                = "s3://${var.kops_state_bucket}"
+  delete_nodes_on_disconnect = var.delete_nodes_on_disconnect
+  credentials_json           = var.kops_credentials
+}
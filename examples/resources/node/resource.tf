@@ -0,0 +1,17 @@
+resource "castai_node" "static" {
+  count = 2
+
+  cluster_id       = castai_eks_cluster.test.id
+  instance_type    = "m5.large"
+  configuration_id = castai_node_configuration.default.id
+
+  kubernetes_labels = {
+    pool = "static"
+  }
+
+  kubernetes_taints {
+    key    = "dedicated"
+    value  = "static"
+    effect = "NoSchedule"
+  }
+}
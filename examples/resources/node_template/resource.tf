@@ -0,0 +1,19 @@
+resource "castai_node_template" "spot" {
+  cluster_id       = castai_eks_cluster.test.id
+  name             = "spot"
+  configuration_id = castai_node_configuration.default.id
+  should_taint     = true
+
+  constraints {
+    spot          = true
+    min_cpu       = 2
+    max_cpu       = 16
+    architectures = ["amd64"]
+  }
+
+  # CAST AI API can't rename node templates, changing the name replaces the template. Create the new template first,
+  # so there is always a template for pods selecting it.
+  lifecycle {
+    create_before_destroy = true
+  }
+}
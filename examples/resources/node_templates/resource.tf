@@ -0,0 +1,30 @@
+locals {
+  node_templates = {
+    spot-amd64 = { architectures = ["amd64"], spot = true }
+    spot-arm64 = { architectures = ["arm64"], spot = true }
+    on-demand  = { architectures = ["amd64", "arm64"], spot = false }
+  }
+}
+
+resource "castai_node_templates" "this" {
+  cluster_id = castai_eks_cluster.test.id
+
+  dynamic "node_template" {
+    for_each = local.node_templates
+
+    content {
+      name             = node_template.key
+      configuration_id = castai_node_configuration.default.id
+      should_taint     = true
+
+      custom_labels = {
+        "team" = node_template.key
+      }
+
+      constraints {
+        spot          = node_template.value.spot
+        architectures = node_template.value.architectures
+      }
+    }
+  }
+}
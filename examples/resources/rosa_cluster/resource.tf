@@ -0,0 +1,7 @@
+resource "castai_rosa_cluster" "this" {
+  name                       = var.rosa_cluster_name
+  region                     = var.region
+  internal_id                = var.rosa_cluster_id
+  delete_nodes_on_disconnect = var.delete_nodes_on_disconnect
+  credentials_json           = var.aws_credentials
+}
@@ -0,0 +1,6 @@
+# Limit total amount of vCPUs in the cluster.
+resource "castai_scaling_policy" "this" {
+  cluster_id = castai_eks_cluster.test.id
+  min_cores  = 4
+  max_cores  = 100
+}